@@ -0,0 +1,7 @@
+// Package config embeds the default catalog shipped inside the binary.
+package config
+
+import "embed"
+
+//go:embed *.yaml
+var FS embed.FS
@@ -0,0 +1,332 @@
+// Package cache keeps downloaded artifacts under $XDG_CACHE_HOME/dev-gadgets,
+// stored by sha256 and indexed by URL, so reinstalls and new containers with
+// the same cache reuse them, and interrupted downloads resume.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// Cache is a download cache rooted at Dir: blobs/<sha256> holds the
+// content, refs/<key>.json maps a URL to its blob and partial/<key> holds
+// unfinished downloads.
+type Cache struct {
+	Dir string
+}
+
+// Default returns the per-user cache.
+func Default() *Cache {
+	return &Cache{Dir: filepath.Join(xdg.CacheHome, "dev-gadgets")}
+}
+
+// Ref is the index entry of a cached URL.
+type Ref struct {
+	URL          string    `json:"url"`
+	SHA256       string    `json:"sha256"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Used         time.Time `json:"used"`
+}
+
+// Fetch returns the path of the cached content of url and its sha256.
+// A blob already known by want (the expected sha256, if any) is used without
+// touching the network; otherwise a cached URL is revalidated with the
+// server, and a new download resumes any partial one left behind.
+// Concurrent fetches of the same URL, from this process or others sharing
+// the cache, take turns: the later ones find the finished download.
+func (c *Cache) Fetch(ctx context.Context, url, want string) (string, string, error) {
+	want = strings.ToLower(want)
+	if p, ok := c.hit(url, want); ok {
+		return p, want, nil
+	}
+
+	key := refKey(url)
+	if err := os.MkdirAll(filepath.Join(c.Dir, "partial"), 0o755); err != nil {
+		return "", "", err
+	}
+	unlock, err := lockFile(filepath.Join(c.Dir, "partial", key+".lock"))
+	if err != nil {
+		return "", "", err
+	}
+	defer unlock()
+	if p, ok := c.hit(url, want); ok {
+		return p, want, nil
+	}
+	return c.fetch(ctx, url, want, key)
+}
+
+// hit returns the blob of want, if it is cached.
+func (c *Cache) hit(url, want string) (string, bool) {
+	if want == "" {
+		return "", false
+	}
+	if _, err := os.Stat(c.blob(want)); err != nil {
+		return "", false
+	}
+	c.touch(url, want)
+	return c.blob(want), true
+}
+
+// fetch revalidates or downloads url; the caller holds the lock of key.
+func (c *Cache) fetch(ctx context.Context, url, want, key string) (string, string, error) {
+	ref, _ := c.readRef(key)
+	if ref != nil {
+		if _, err := os.Stat(c.blob(ref.SHA256)); err != nil || (want != "" && want != ref.SHA256) {
+			ref = nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("User-Agent", "dev-gadgets")
+	if ref != nil {
+		if ref.ETag != "" {
+			req.Header.Set("If-None-Match", ref.ETag)
+		}
+		if ref.LastModified != "" {
+			req.Header.Set("If-Modified-Since", ref.LastModified)
+		}
+	}
+	part := filepath.Join(c.Dir, "partial", key)
+	offset := int64(0)
+	if fi, err := os.Stat(part); err == nil && ref == nil {
+		// Resume only if the server still has the same file.
+		if etag, err := os.ReadFile(part + ".etag"); err == nil && len(etag) > 0 {
+			offset = fi.Size()
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Range", string(etag))
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ref != nil:
+		c.touch(url, ref.SHA256)
+		return c.blob(ref.SHA256), ref.SHA256, nil
+	case resp.StatusCode == http.StatusPartialContent && offset > 0 &&
+		strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)):
+	case resp.StatusCode == http.StatusOK:
+		offset = 0 // the server ignored the range or the file changed; start over
+	case offset > 0 && (resp.StatusCode == http.StatusPartialContent || resp.StatusCode == http.StatusRequestedRangeNotSatisfiable):
+		// The partial file does not fit what the server has; drop it and retry.
+		os.Remove(part)
+		return c.fetch(ctx, url, want, key)
+	default:
+		return "", "", fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return "", "", err
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		os.WriteFile(part+".etag", []byte(etag), 0o644)
+	} else {
+		os.Remove(part + ".etag")
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return "", "", fmt.Errorf("GET %s: %v (partial download kept)", url, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", "", err
+	}
+
+	sum, size, err := hashFile(part)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(filepath.Join(c.Dir, "blobs"), 0o755); err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(part, c.blob(sum)); err != nil {
+		return "", "", err
+	}
+	os.Remove(part + ".etag")
+	err = c.writeRef(key, Ref{
+		URL:          url,
+		SHA256:       sum,
+		Size:         size,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Used:         time.Now().UTC(),
+	})
+	return c.blob(sum), sum, err
+}
+
+// List returns the cached URLs, most recently used first.
+func (c *Cache) List() ([]Ref, error) {
+	entries, err := os.ReadDir(filepath.Join(c.Dir, "refs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var refs []Ref
+	for _, e := range entries {
+		if r, err := c.readRef(strings.TrimSuffix(e.Name(), ".json")); err == nil {
+			refs = append(refs, *r)
+		}
+	}
+	slices.SortFunc(refs, func(a, b Ref) int { return b.Used.Compare(a.Used) })
+	return refs, nil
+}
+
+// Clean removes the whole cache.
+func (c *Cache) Clean() error {
+	return os.RemoveAll(c.Dir)
+}
+
+// GC drops URLs not used within maxAge, blobs no URL points to and partial
+// downloads older than maxAge. It returns the number of bytes freed, or
+// that would be with dryRun, which removes nothing.
+func (c *Cache) GC(maxAge time.Duration, dryRun bool) (int64, error) {
+	cutoff := time.Now().Add(-maxAge)
+	refs, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+	live := map[string]bool{}
+	for _, r := range refs {
+		if r.Used.Before(cutoff) {
+			if !dryRun {
+				os.Remove(filepath.Join(c.Dir, "refs", refKey(r.URL)+".json"))
+			}
+			continue
+		}
+		live[r.SHA256] = true
+	}
+
+	var freed int64
+	sweep := func(dir string, keep func(fs.DirEntry, fs.FileInfo) bool) error {
+		entries, err := os.ReadDir(filepath.Join(c.Dir, dir))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			fi, err := e.Info()
+			if err != nil || keep(e, fi) {
+				continue
+			}
+			if dryRun {
+				freed += fi.Size()
+			} else if err := os.Remove(filepath.Join(c.Dir, dir, e.Name())); err == nil {
+				freed += fi.Size()
+			}
+		}
+		return nil
+	}
+	if err := sweep("blobs", func(e fs.DirEntry, _ fs.FileInfo) bool { return live[e.Name()] }); err != nil {
+		return freed, err
+	}
+	// Lock files stay: removing one another fetch holds would let a third
+	// fetch lock a new file and download alongside it.
+	err = sweep("partial", func(e fs.DirEntry, fi fs.FileInfo) bool {
+		return strings.HasSuffix(e.Name(), ".lock") || fi.ModTime().After(cutoff)
+	})
+	return freed, err
+}
+
+func (c *Cache) blob(sum string) string {
+	return filepath.Join(c.Dir, "blobs", sum)
+}
+
+// touch records that url (served as blob sum) was used now.
+func (c *Cache) touch(url, sum string) {
+	key := refKey(url)
+	r, err := c.readRef(key)
+	if err != nil || r.SHA256 != sum {
+		fi, err := os.Stat(c.blob(sum))
+		if err != nil {
+			return
+		}
+		r = &Ref{URL: url, SHA256: sum, Size: fi.Size()}
+	}
+	r.Used = time.Now().UTC()
+	c.writeRef(key, *r)
+}
+
+func (c *Cache) readRef(key string) (*Ref, error) {
+	b, err := os.ReadFile(filepath.Join(c.Dir, "refs", key+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var r Ref
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (c *Cache) writeRef(key string, r Ref) error {
+	dir := filepath.Join(c.Dir, "refs")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+key+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, key+".json"))
+}
+
+func refKey(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(h[:16])
+}
+
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
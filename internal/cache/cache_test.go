@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGC(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("content of " + r.URL.Path))
+	}))
+	defer srv.Close()
+
+	c := &Cache{Dir: t.TempDir()}
+	ctx := context.Background()
+	oldPath, _, err := c.Fetch(ctx, srv.URL+"/old", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPath, _, err := c.Fetch(ctx, srv.URL+"/new", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _ := c.readRef(refKey(srv.URL + "/old"))
+	old.Used = time.Now().Add(-48 * time.Hour)
+	if err := c.writeRef(refKey(old.URL), *old); err != nil {
+		t.Fatal(err)
+	}
+
+	freed, err := c.GC(24*time.Hour, true)
+	if err != nil || freed != old.Size {
+		t.Fatalf("dry run GC = %d, %v; want %d", freed, err, old.Size)
+	}
+	if refs, _ := c.List(); len(refs) != 2 {
+		t.Errorf("dry run left %d refs, want 2", len(refs))
+	}
+	if _, err := os.Stat(oldPath); err != nil {
+		t.Errorf("dry run removed a blob: %v", err)
+	}
+
+	freed, err = c.GC(24*time.Hour, false)
+	if err != nil || freed != old.Size {
+		t.Fatalf("GC = %d, %v; want %d", freed, err, old.Size)
+	}
+	refs, _ := c.List()
+	if len(refs) != 1 || refs[0].URL != srv.URL+"/new" {
+		t.Errorf("refs after GC = %+v", refs)
+	}
+	if _, err := os.Stat(oldPath); err == nil {
+		t.Error("GC kept the unused blob")
+	}
+	if _, err := os.Stat(newPath); err != nil {
+		t.Errorf("GC removed a used blob: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(c.Dir, "blobs")); len(entries) != 1 {
+		t.Errorf("%d blobs left, want 1", len(entries))
+	}
+}
+
+func TestFetchConcurrent(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100_000)
+	var full atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == "" {
+			full.Add(1)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		// Trickle the body so the fetches overlap.
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		for i := 0; i < len(content); i += 100_000 {
+			w.Write(content[i : i+100_000])
+			w.(http.Flusher).Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	sums := make([]string, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Separate caches over one dir, like processes sharing it.
+			c := &Cache{Dir: dir}
+			var p string
+			p, sums[i], errs[i] = c.Fetch(context.Background(), srv.URL+"/tool.tar.gz", "")
+			if errs[i] == nil {
+				if b, err := os.ReadFile(p); err != nil || !bytes.Equal(b, content) {
+					errs[i] = fmt.Errorf("cached content differs: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("fetch %d: %v", i, err)
+		}
+		if sums[i] != sums[0] {
+			t.Errorf("fetch %d: sha256 %s, want %s", i, sums[i], sums[0])
+		}
+	}
+	if n := full.Load(); n != 1 {
+		t.Errorf("%d full downloads, want 1 (the others revalidate)", n)
+	}
+}
+
+func TestFetchResume(t *testing.T) {
+	content := []byte(strings.Repeat("abcdefghij", 1000))
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "tool", time.Unix(1e9, 0), bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	c := &Cache{Dir: t.TempDir()}
+	url := srv.URL + "/tool"
+	part := filepath.Join(c.Dir, "partial", refKey(url))
+	os.MkdirAll(filepath.Dir(part), 0o755)
+	os.WriteFile(part, content[:4000], 0o644)
+	os.WriteFile(part+".etag", []byte(`"v1"`), 0o644)
+
+	p, _, err := c.Fetch(context.Background(), url, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(p); !bytes.Equal(b, content) {
+		t.Error("resumed download differs from the original")
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=4000-" {
+		t.Errorf("requested ranges %q, want [bytes=4000-]", ranges)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !unix
+
+package cache
+
+import "sync"
+
+var (
+	locksMu sync.Mutex
+	locks   = map[string]*sync.Mutex{}
+)
+
+// lockFile serializes fetches of path within this process only; other
+// processes sharing the cache are not excluded on this platform.
+func lockFile(path string) (func(), error) {
+	locksMu.Lock()
+	mu, ok := locks[path]
+	if !ok {
+		mu = &sync.Mutex{}
+		locks[path] = mu
+	}
+	locksMu.Unlock()
+	mu.Lock()
+	return mu.Unlock, nil
+}
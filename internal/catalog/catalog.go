@@ -2,55 +2,301 @@ package catalog
 
 import (
 	"errors"
-	"os"
+	"fmt"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"slices"
 	"strings"
+	"text/template"
 
+	"github.com/pirpedro/dev-gadgets/internal/semver"
 	"gopkg.in/yaml.v3"
 )
 
 type Strategy struct {
-	Brew    string            `yaml:"brew,omitempty"`
-	Apt     string            `yaml:"apt,omitempty"`
-	Dnf     string            `yaml:"dnf,omitempty"`
-	Pacman  string            `yaml:"pacman,omitempty"`
-	Zypper  string            `yaml:"zypper,omitempty"`
-	Pipx    string            `yaml:"pipx,omitempty"`
-	Uv      string            `yaml:"uv,omitempty"`
-	Npm     string            `yaml:"npm,omitempty"`
-	Volta   string            `yaml:"volta,omitempty"`
-	Release map[string]string `yaml:"release,omitempty"` // url, bin
+	Brew   string `yaml:"brew,omitempty"`
+	Apt    string `yaml:"apt,omitempty"`
+	Apk    string `yaml:"apk,omitempty"`
+	Dnf    string `yaml:"dnf,omitempty"`
+	Pacman string `yaml:"pacman,omitempty"`
+	Zypper string `yaml:"zypper,omitempty"`
+	Pipx   string `yaml:"pipx,omitempty"`
+	Uv     string `yaml:"uv,omitempty"`
+	Npm    string `yaml:"npm,omitempty"`
+	Volta  string `yaml:"volta,omitempty"`
+	Go     string `yaml:"go,omitempty"` // module path of the main package
+	Cargo  *Cargo `yaml:"cargo,omitempty"`
+	Mise   string `yaml:"mise,omitempty"` // mise/asdf plugin name, e.g. node
+	Nix    *Nix   `yaml:"nix,omitempty"`
+	// Container runs the tool from an image through a shim in the bin dir.
+	Container *Container `yaml:"container,omitempty"`
+	Release   *Release   `yaml:"release,omitempty"`
+	GitHub    *GitHub    `yaml:"github,omitempty"`
+	Script    *Script    `yaml:"script,omitempty"`
+
+	// Order is set when strategies are written as a list and keeps the
+	// item's preferred order, e.g. [{apt: x}, {release: {...}}].
+	Order []string `yaml:"-"`
+}
+
+// Release is a downloadable artifact. URL and Bin are templates over
+// {{.Os}}, {{.Arch}}, {{.Libc}} and {{.Version}}; the maps translate Go's
+// names (amd64, darwin, musl) into the vendor's spelling (x86_64, macOS, ...).
+type Release struct {
+	URL     string            `yaml:"url"`
+	Bin     string            `yaml:"bin,omitempty"` // defaults to the item ID
+	OS      map[string]string `yaml:"os,omitempty"`
+	Arch    map[string]string `yaml:"arch,omitempty"`
+	Libc    map[string]string `yaml:"libc,omitempty"`
+	Payload `yaml:",inline"`
+	// SHA256 or Checksums (a URL template of a sha256sum-style file such as
+	// GoReleaser's checksums.txt) is what the download is checked against.
+	SHA256    string     `yaml:"sha256,omitempty"`
+	Checksums string     `yaml:"checksums,omitempty"`
+	Signature *Signature `yaml:"signature,omitempty"`
+}
+
+// Cargo is a crate installed with cargo binstall or cargo install. It may be
+// written as just the crate name.
+type Cargo struct {
+	Crate  string `yaml:"crate"`
+	Locked bool   `yaml:"locked,omitempty"` // build with the crate's Cargo.lock
+}
+
+func (c *Cargo) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		c.Crate = n.Value
+		return nil
+	}
+	type plain Cargo
+	return n.Decode((*plain)(c))
+}
+
+// Nix is a flake output installed with nix profile install. It may be
+// written as just the attribute, which is then taken from nixpkgs.
+type Nix struct {
+	Attr  string `yaml:"attr"`
+	Flake string `yaml:"flake,omitempty"` // defaults to nixpkgs
+}
+
+func (x *Nix) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		x.Attr = n.Value
+		return nil
+	}
+	type plain Nix
+	return n.Decode((*plain)(x))
+}
+
+// Ref is the installable, e.g. nixpkgs#ripgrep.
+func (x Nix) Ref() string {
+	flake := x.Flake
+	if flake == "" {
+		flake = "nixpkgs"
+	}
+	return flake + "#" + x.Attr
+}
+
+// Container is an image run with docker or podman, with the working dir
+// mounted and the caller's uid. It may be written as just the image.
+type Container struct {
+	Image      string `yaml:"image"`         // tagged with the pinned version when it has no tag
+	Bin        string `yaml:"bin,omitempty"` // shim name, defaults to the item ID
+	Entrypoint string `yaml:"entrypoint,omitempty"`
+}
+
+func (c *Container) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		c.Image = n.Value
+		return nil
+	}
+	type plain Container
+	return n.Decode((*plain)(c))
+}
+
+// Script installs a tool no package manager covers. Steps run with sh in a
+// temp dir and a minimal environment; Outputs are the files they produce,
+// relative to that dir, which are checked and copied into the bin dir.
+type Script struct {
+	Steps   []string          `yaml:"steps"`
+	Outputs []string          `yaml:"outputs"`
+	Env     map[string]string `yaml:"env,omitempty"` // added to the step environment
+}
+
+// GitHub is a release resolved through the GitHub API: the latest one, or
+// the tag of the pinned version. The asset is picked by matching its name
+// against OS and arch patterns; the built-in ones cover the usual spellings
+// and OS/Arch (keyed by Go's names) replace them. It may be written as just
+// owner/repo.
+type GitHub struct {
+	Repo    string            `yaml:"repo"`
+	Asset   string            `yaml:"asset,omitempty"` // extra pattern the asset name must match
+	Bin     string            `yaml:"bin,omitempty"`   // defaults to the item ID
+	OS      map[string]string `yaml:"os,omitempty"`
+	Arch    map[string]string `yaml:"arch,omitempty"`
+	Payload `yaml:",inline"`
+	// SHA256 or Checksums (a pattern naming the checksums asset, e.g.
+	// checksums\.txt$) is what the download is checked against.
+	SHA256    string     `yaml:"sha256,omitempty"`
+	Checksums string     `yaml:"checksums,omitempty"`
+	Signature *Signature `yaml:"signature,omitempty"`
+}
+
+func (g *GitHub) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		g.Repo = n.Value
+		return nil
+	}
+	type plain GitHub
+	return n.Decode((*plain)(g))
+}
+
+// Payload lists what a release archive provides. Bins replaces the single
+// Bin; Completions (keyed by shell) and Man are paths inside the archive,
+// installed where the shells and man look for them. Paths without a slash
+// match any file with that name.
+type Payload struct {
+	Bins        []string          `yaml:"bins,omitempty"`
+	Completions map[string]string `yaml:"completions,omitempty"`
+	Man         []string          `yaml:"man,omitempty"`
+}
+
+func (p Payload) validate() error {
+	for sh := range p.Completions {
+		if !slices.Contains(Shells, sh) {
+			return fmt.Errorf("completions: unknown shell %q", sh)
+		}
+	}
+	return nil
+}
+
+// Signature is a detached signature checked offline before a download is
+// trusted. It covers the checksums file when one is set, else the artifact.
+type Signature struct {
+	Minisign string `yaml:"minisign,omitempty"` // minisign public key (RW...)
+	Cosign   string `yaml:"cosign,omitempty"`   // PEM public key of cosign sign-blob --key
+	// URL of the signature: a URL template for release, an asset pattern for
+	// github. Defaults to the signed file plus .minisig or .sig.
+	URL string `yaml:"url,omitempty"`
+}
+
+func (s *Signature) validate() error {
+	if (s.Minisign == "") == (s.Cosign == "") {
+		return errors.New("signature needs exactly one of minisign or cosign")
+	}
+	return nil
+}
+
+// UnmarshalYAML accepts both the mapping form and an ordered list of
+// single-key mappings.
+func (s *Strategy) UnmarshalYAML(n *yaml.Node) error {
+	type plain Strategy
+	if n.Kind != yaml.SequenceNode {
+		return n.Decode((*plain)(s))
+	}
+	for _, e := range n.Content {
+		if e.Kind != yaml.MappingNode || len(e.Content) != 2 {
+			return fmt.Errorf("line %d: each strategy in the list must be a single-key mapping", e.Line)
+		}
+		if err := e.Decode((*plain)(s)); err != nil {
+			return err
+		}
+		s.Order = append(s.Order, e.Content[0].Value)
+	}
+	return nil
+}
+
+// Declared reports whether the strategy named after its yaml key is set.
+func (s Strategy) Declared(name string) bool {
+	v := reflect.ValueOf(s)
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if tag, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); tag == name {
+			return !v.FieldByIndex(f.Index).IsZero()
+		}
+	}
+	return false
+}
+
+// StrategyNames lists the strategy keys known to the catalog.
+func StrategyNames() []string {
+	var names []string
+	for name := range yamlFields(reflect.TypeOf(Strategy{})) {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
 }
 
 type Item struct {
-	ID          string   `yaml:"id"`
-	Name        string   `yaml:"name"`
-	Description string   `yaml:"description,omitempty"`
-	Verify      string   `yaml:"verify"` // e.g., "git-town --version"
-	Strategy    Strategy `yaml:"strategies"`
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Verify      string `yaml:"verify"` // e.g., "git-town --version"
+	// Version is an exact version or a constraint (">=14", "^2.1") that the
+	// output of Verify must satisfy; exact versions are pinned on install.
+	Version      string   `yaml:"version,omitempty"`
+	VersionRegex string   `yaml:"version_regex,omitempty"` // extracts the version from Verify output
+	Strategy     Strategy `yaml:"strategies"`
+	Requires     []string `yaml:"requires,omitempty"` // IDs installed before this item
+	Tags         []string `yaml:"tags,omitempty"`     // e.g. git, release, python
+	PostInstall  []Hook   `yaml:"post_install,omitempty"`
+	Notes        string   `yaml:"notes,omitempty"` // shown in the install summary
+	Shell        *Shell   `yaml:"shell,omitempty"`
+	Disabled     bool     `yaml:"disabled,omitempty"` // drops an item declared by an earlier layer
 }
 
-type Config struct {
-	Items  []Item   `yaml:"items"`
-	Curate []string `yaml:"curate,omitempty"`
+// Hook is a shell command run after an item is installed and verified.
+type Hook struct {
+	Run string `yaml:"run"`
+	// When restricts the hook: "git-repo" (inside a git work tree),
+	// "file:<path>" (path exists) or "env:<VAR>" (VAR is set).
+	When string `yaml:"when,omitempty"`
+	// Always also runs the hook when the item was already installed.
+	Always bool `yaml:"always,omitempty"`
 }
 
-func Load() (*Config, error) {
-	path := filepath.Join("config", "catalog.yaml")
-	b, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
+// Valid reports whether the hook's condition is one of the known forms.
+func (h Hook) Valid() error {
+	if strings.TrimSpace(h.Run) == "" {
+		return errors.New("post_install: run is required")
 	}
-	var c Config
-	if err := yaml.Unmarshal(b, &c); err != nil {
-		return nil, err
+	switch kind, arg, _ := strings.Cut(h.When, ":"); {
+	case h.When == "", h.When == "git-repo":
+	case (kind == "file" || kind == "env") && arg != "":
+	default:
+		return fmt.Errorf("post_install: unknown condition %q", h.When)
 	}
+	return nil
+}
+
+// Shells supported by shell-init.
+var Shells = []string{"bash", "zsh", "fish"}
+
+// Shell is the shell integration of an item, printed by shell-init once the
+// item is installed. Init and Completions are keyed by shell name.
+type Shell struct {
+	Path        []string          `yaml:"path,omitempty"` // prepended to PATH; ~ and $VARS expand
+	Env         map[string]string `yaml:"env,omitempty"`
+	Init        map[string]string `yaml:"init,omitempty"` // e.g. bash: eval "$(tool init bash)"
+	Completions map[string]string `yaml:"completions,omitempty"`
+}
+
+type Config struct {
+	// Include lists catalog fragments merged before this file, relative to it.
+	Include []string `yaml:"include,omitempty"`
+	Items   []Item   `yaml:"items"`
+	Curate  []string `yaml:"curate,omitempty"` // items of the default profile
+	// Profiles are named item selections, see ProfileItems.
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+	// Prefer moves these strategies to the front of every item's order.
+	Prefer []string `yaml:"prefer,omitempty"`
+
+	// Sources lists the catalog files that were merged, in load order.
+	Sources []string `yaml:"-"`
 
-	slices.SortFunc(c.Items, func(a, b Item) int {
-		return strings.Compare(a.ID, b.ID)
-	})
-	return &c, nil
+	docs   []document
+	loaded map[string]bool // files merged so far, keyed by layer and name
 }
 
 func (c *Config) ByIDs(ids []string) []Item {
@@ -74,9 +320,144 @@ func (c *Config) Curated() []Item {
 	return c.ByIDs(c.Curate)
 }
 
+// FilterTags keeps the items carrying any of the include tags (all items when
+// include is empty) and none of the exclude tags.
+func FilterTags(items []Item, include, exclude []string) []Item {
+	var out []Item
+	for _, it := range items {
+		if len(include) > 0 && !slices.ContainsFunc(include, it.HasTag) {
+			continue
+		}
+		if slices.ContainsFunc(exclude, it.HasTag) {
+			continue
+		}
+		out = append(out, it)
+	}
+	return out
+}
+
+func (i Item) HasTag(tag string) bool {
+	return slices.Contains(i.Tags, tag)
+}
+
 func (i Item) Validate() error {
 	if i.ID == "" || i.Name == "" {
 		return errors.New("invalid item: id/name required")
 	}
+	if reflect.ValueOf(i.Strategy).IsZero() {
+		return fmt.Errorf("invalid item %s: no strategies", i.ID)
+	}
+	if i.Version != "" {
+		if _, err := semver.ParseConstraint(i.Version); err != nil {
+			return fmt.Errorf("invalid item %s: %v", i.ID, err)
+		}
+		if i.Verify == "" {
+			return fmt.Errorf("invalid item %s: version requires a verify command", i.ID)
+		}
+	}
+	if r := i.Strategy.Release; r != nil {
+		if r.URL == "" {
+			return fmt.Errorf("invalid item %s: release requires a url", i.ID)
+		}
+		if err := checkSHA256(r.SHA256); err != nil {
+			return fmt.Errorf("invalid item %s: release: %v", i.ID, err)
+		}
+		if r.Signature != nil {
+			if err := r.Signature.validate(); err != nil {
+				return fmt.Errorf("invalid item %s: release: %v", i.ID, err)
+			}
+		}
+		if err := r.Payload.validate(); err != nil {
+			return fmt.Errorf("invalid item %s: release: %v", i.ID, err)
+		}
+		templates := append([]string{r.URL, r.Bin, r.Checksums}, r.Bins...)
+		templates = append(templates, r.Man...)
+		for _, c := range r.Completions {
+			templates = append(templates, c)
+		}
+		if r.Signature != nil {
+			templates = append(templates, r.Signature.URL)
+		}
+		for _, t := range templates {
+			if _, err := template.New("release").Parse(t); err != nil {
+				return fmt.Errorf("invalid item %s: release: %v", i.ID, err)
+			}
+		}
+	}
+	if g := i.Strategy.GitHub; g != nil {
+		if owner, repo, ok := strings.Cut(g.Repo, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return fmt.Errorf("invalid item %s: github: repo must be owner/repo, got %q", i.ID, g.Repo)
+		}
+		if err := g.Payload.validate(); err != nil {
+			return fmt.Errorf("invalid item %s: github: %v", i.ID, err)
+		}
+		if err := checkSHA256(g.SHA256); err != nil {
+			return fmt.Errorf("invalid item %s: github: %v", i.ID, err)
+		}
+		patterns := []string{g.Asset, g.Checksums}
+		if g.Signature != nil {
+			if err := g.Signature.validate(); err != nil {
+				return fmt.Errorf("invalid item %s: github: %v", i.ID, err)
+			}
+			patterns = append(patterns, g.Signature.URL)
+		}
+		for _, m := range []map[string]string{g.OS, g.Arch} {
+			for _, p := range m {
+				patterns = append(patterns, p)
+			}
+		}
+		for _, p := range patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("invalid item %s: github: %v", i.ID, err)
+			}
+		}
+	}
+	if c := i.Strategy.Cargo; c != nil && c.Crate == "" {
+		return fmt.Errorf("invalid item %s: cargo requires a crate", i.ID)
+	}
+	if x := i.Strategy.Nix; x != nil && x.Attr == "" {
+		return fmt.Errorf("invalid item %s: nix requires an attr", i.ID)
+	}
+	if c := i.Strategy.Container; c != nil && c.Image == "" {
+		return fmt.Errorf("invalid item %s: container requires an image", i.ID)
+	}
+	if sc := i.Strategy.Script; sc != nil {
+		if len(sc.Steps) == 0 || len(sc.Outputs) == 0 {
+			return fmt.Errorf("invalid item %s: script requires steps and outputs", i.ID)
+		}
+		for _, out := range sc.Outputs {
+			if !filepath.IsLocal(out) {
+				return fmt.Errorf("invalid item %s: script output %q must be relative to the work dir", i.ID, out)
+			}
+		}
+	}
+	for _, h := range i.PostInstall {
+		if err := h.Valid(); err != nil {
+			return fmt.Errorf("invalid item %s: %v", i.ID, err)
+		}
+	}
+	if i.Shell != nil {
+		for _, m := range []map[string]string{i.Shell.Init, i.Shell.Completions} {
+			for sh := range m {
+				if !slices.Contains(Shells, sh) {
+					return fmt.Errorf("invalid item %s: shell: unknown shell %q", i.ID, sh)
+				}
+			}
+		}
+	}
+	if i.VersionRegex != "" {
+		if _, err := regexp.Compile(i.VersionRegex); err != nil {
+			return fmt.Errorf("invalid item %s: version_regex: %v", i.ID, err)
+		}
+	}
+	return nil
+}
+
+var sha256Re = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+func checkSHA256(sum string) error {
+	if sum != "" && !sha256Re.MatchString(sum) {
+		return fmt.Errorf("sha256 must be 64 hex digits, got %q", sum)
+	}
 	return nil
 }
@@ -0,0 +1,106 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Plan expands items with their missing requirements and groups them into
+// stages: every item only depends on items of earlier stages, so the items
+// of one stage can be installed concurrently.
+func (c *Config) Plan(items []Item) ([][]Item, error) {
+	byID := map[string]Item{}
+	for _, it := range c.Items {
+		byID[it.ID] = it
+	}
+
+	// Pull in requirements transitively.
+	selected := map[string]Item{}
+	queue := slices.Clone(items)
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		if _, ok := selected[it.ID]; ok {
+			continue
+		}
+		selected[it.ID] = it
+		for _, dep := range it.Requires {
+			d, ok := byID[dep]
+			if !ok {
+				return nil, fmt.Errorf("%s requires unknown item %q", it.ID, dep)
+			}
+			queue = append(queue, d)
+		}
+	}
+
+	if cycle := findCycle(selected); cycle != nil {
+		return nil, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	// Kahn's algorithm, one stage per round.
+	done := map[string]bool{}
+	var stages [][]Item
+	for len(done) < len(selected) {
+		var stage []Item
+		for id, it := range selected {
+			if done[id] {
+				continue
+			}
+			if !slices.ContainsFunc(it.Requires, func(dep string) bool { return !done[dep] }) {
+				stage = append(stage, it)
+			}
+		}
+		slices.SortFunc(stage, func(a, b Item) int { return strings.Compare(a.ID, b.ID) })
+		for _, it := range stage {
+			done[it.ID] = true
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// findCycle returns the IDs along a requirement cycle in items, if any.
+func findCycle(items map[string]Item) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case visiting:
+			i := slices.Index(path, id)
+			return append(slices.Clone(path[i:]), id)
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range items[id].Requires {
+			if _, ok := items[dep]; !ok {
+				continue
+			}
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		if cycle := visit(id); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
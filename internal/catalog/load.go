@@ -0,0 +1,228 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/pirpedro/dev-gadgets/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvCatalog selects a catalog file explicitly, like the --catalog flag.
+	EnvCatalog = "DEV_GADGETS_CATALOG"
+	// RepoCatalog is the repo-local catalog looked up from the working dir.
+	RepoCatalog = ".dev-gadgets.yaml"
+
+	defaultCatalog = "catalog.yaml"
+)
+
+// Load builds the catalog. An explicit path (or $DEV_GADGETS_CATALOG) is used
+// on its own; otherwise the embedded default, the user catalog under the XDG
+// config dir and the repo-local .dev-gadgets.yaml are merged by item ID, later
+// layers overriding or disabling items of earlier ones. Each file may pull in
+// fragments with include:, resolved relative to it.
+func Load(explicit string) (*Config, error) {
+	return load(explicit, true)
+}
+
+// LoadUser is Load without the repo-local catalog: only the embedded default
+// and the user catalog, which a cloned repository cannot change. Output that
+// a shell evaluates at startup must come from these.
+func LoadUser(explicit string) (*Config, error) {
+	return load(explicit, false)
+}
+
+func load(explicit string, repo bool) (*Config, error) {
+	if explicit == "" {
+		explicit = os.Getenv(EnvCatalog)
+	}
+
+	var c Config
+	if explicit != "" {
+		if err := c.load(osSource{}, explicit, nil); err != nil {
+			return nil, err
+		}
+		return c.finish(), nil
+	}
+
+	if err := c.load(embeddedSource{}, defaultCatalog, nil); err != nil {
+		return nil, err
+	}
+	paths := []string{UserCatalogPath()}
+	if repo {
+		paths = append(paths, findRepoCatalog())
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err := c.load(osSource{}, path, nil); err != nil {
+			return nil, err
+		}
+	}
+	return c.finish(), nil
+}
+
+// UserCatalogPath returns the per-user catalog location.
+func UserCatalogPath() string {
+	return filepath.Join(xdg.ConfigHome, "dev-gadgets", "catalog.yaml")
+}
+
+// findRepoCatalog returns the repo-local catalog path, if there is one.
+func findRepoCatalog() string {
+	path := filepath.Join(RepoDir(), RepoCatalog)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// RepoDir walks up from the working dir to the first directory holding
+// RepoCatalog or .git, falling back to the working dir itself.
+func RepoDir() string {
+	dir, _ := FindRepoDir()
+	return dir
+}
+
+// FindRepoDir is RepoDir, also reporting whether a repo root was found
+// rather than falling back to the working dir.
+func FindRepoDir() (string, bool) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ".", false
+	}
+	for dir := wd; ; {
+		for _, marker := range []string{RepoCatalog, ".git"} {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return wd, false
+		}
+		dir = parent
+	}
+}
+
+// source reads catalog files and resolves includes relative to them.
+type source interface {
+	read(name string) ([]byte, error)
+	join(from, rel string) string
+	display(name string) string
+}
+
+type osSource struct{}
+
+func (osSource) read(name string) ([]byte, error) { return os.ReadFile(name) }
+func (osSource) display(name string) string       { return name }
+
+func (osSource) join(from, rel string) string {
+	if filepath.IsAbs(rel) {
+		return rel
+	}
+	return filepath.Join(filepath.Dir(from), rel)
+}
+
+// embeddedSource reads the default catalog and its fragments from the binary.
+type embeddedSource struct{}
+
+func (embeddedSource) read(name string) ([]byte, error) { return fs.ReadFile(config.FS, name) }
+func (embeddedSource) join(from, rel string) string     { return path.Join(path.Dir(from), rel) }
+func (embeddedSource) display(name string) string       { return "embedded:" + name }
+
+// load merges the catalog file name, after the files it includes so that
+// its own items override theirs. stack holds the includes being resolved.
+func (c *Config) load(src source, name string, stack []string) error {
+	if i := slices.Index(stack, name); i >= 0 {
+		cycle := append(slices.Clone(stack[i:]), name)
+		for j := range cycle {
+			cycle[j] = src.display(cycle[j])
+		}
+		return fmt.Errorf("include cycle: %s", strings.Join(cycle, " -> "))
+	}
+	// A fragment included by several files of a layer is merged once.
+	top := name
+	if len(stack) > 0 {
+		top = stack[0]
+	}
+	key := src.display(top) + "\x00" + src.display(name)
+	if c.loaded[key] {
+		return nil
+	}
+	if c.loaded == nil {
+		c.loaded = map[string]bool{}
+	}
+	c.loaded[key] = true
+	b, err := src.read(name)
+	if err != nil {
+		if len(stack) > 0 {
+			return fmt.Errorf("%s: include: %v", src.display(stack[len(stack)-1]), err)
+		}
+		return err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return fmt.Errorf("%s: %v", src.display(name), err)
+	}
+	var layer Config
+	if err := root.Decode(&layer); err != nil {
+		return fmt.Errorf("%s: %v", src.display(name), err)
+	}
+	for _, inc := range layer.Include {
+		if err := c.load(src, src.join(name, inc), append(stack, name)); err != nil {
+			return err
+		}
+	}
+	includedBy := make([]string, len(stack))
+	for i, s := range stack {
+		includedBy[i] = src.display(s)
+	}
+	c.merge(document{name: src.display(name), root: &root, includedBy: includedBy}, &layer)
+	return nil
+}
+
+// merge overlays a parsed catalog file on top of c.
+func (c *Config) merge(d document, layer *Config) {
+	c.docs = append(c.docs, d)
+	for _, it := range layer.Items {
+		i := slices.IndexFunc(c.Items, func(x Item) bool { return x.ID == it.ID })
+		if i >= 0 {
+			c.Items[i] = it
+		} else {
+			c.Items = append(c.Items, it)
+		}
+	}
+	if len(layer.Curate) > 0 {
+		c.Curate = layer.Curate
+	}
+	for name, p := range layer.Profiles {
+		if c.Profiles == nil {
+			c.Profiles = map[string]Profile{}
+		}
+		c.Profiles[name] = p
+	}
+	if len(layer.Prefer) > 0 {
+		c.Prefer = layer.Prefer
+	}
+	c.Sources = append(c.Sources, d.name)
+}
+
+func (c *Config) finish() *Config {
+	c.Items = slices.DeleteFunc(c.Items, func(it Item) bool { return it.Disabled })
+	slices.SortFunc(c.Items, func(a, b Item) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return c
+}
@@ -0,0 +1,82 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultProfile is installed by --all and when nothing else is selected.
+// Catalogs without such a profile fall back to their curate list.
+const DefaultProfile = "default"
+
+// Profile is a named selection of items.
+type Profile struct {
+	Description string   `yaml:"description,omitempty"`
+	Include     []string `yaml:"include,omitempty"` // other profiles
+	Items       []string `yaml:"items,omitempty"`
+	Exclude     []string `yaml:"exclude,omitempty"` // removed after includes are expanded
+}
+
+// ProfileNames returns the defined profiles, default included.
+func (c *Config) ProfileNames() []string {
+	names := []string{DefaultProfile}
+	for name := range c.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names[1:])
+	return names
+}
+
+// LookupProfile returns the named profile; "default" is built from curate
+// when the catalog does not define it.
+func (c *Config) LookupProfile(name string) (Profile, bool) {
+	if p, ok := c.Profiles[name]; ok {
+		return p, true
+	}
+	if name != DefaultProfile {
+		return Profile{}, false
+	}
+	p := Profile{Description: "curated defaults", Items: c.Curate}
+	if len(p.Items) == 0 {
+		for _, it := range c.Items {
+			p.Items = append(p.Items, it.ID)
+		}
+	}
+	return p, true
+}
+
+// ProfileItems resolves the items of a profile and of the profiles it
+// includes, minus its exclusions. Unknown item IDs are skipped.
+func (c *Config) ProfileItems(name string) ([]Item, error) {
+	ids, err := c.profileIDs(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.ByIDs(ids), nil
+}
+
+func (c *Config) profileIDs(name string, stack []string) ([]string, error) {
+	if slices.Contains(stack, name) {
+		return nil, fmt.Errorf("profile cycle: %s", strings.Join(append(stack, name), " -> "))
+	}
+	p, ok := c.LookupProfile(name)
+	if !ok {
+		if len(stack) > 0 {
+			return nil, fmt.Errorf("profile %s includes unknown profile %q", stack[len(stack)-1], name)
+		}
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+	var ids []string
+	for _, inc := range p.Include {
+		sub, err := c.profileIDs(inc, append(stack, name))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, sub...)
+	}
+	ids = append(ids, p.Items...)
+	return slices.DeleteFunc(ids, func(id string) bool { return slices.Contains(p.Exclude, id) }), nil
+}
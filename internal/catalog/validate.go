@@ -0,0 +1,283 @@
+package catalog
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is one parsed catalog file, kept around for diagnostics.
+type document struct {
+	name string
+	root *yaml.Node
+	// includedBy is the include chain that led to the file, starting with
+	// the top-level file of its layer.
+	includedBy []string
+}
+
+// layer names the top-level catalog file (embedded, user, repo or explicit)
+// the document belongs to.
+func (d document) layer() string {
+	if len(d.includedBy) > 0 {
+		return d.includedBy[0]
+	}
+	return d.name
+}
+
+// Diagnostic is a catalog problem located in its source file.
+type Diagnostic struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+}
+
+// Diagnostics is returned by Validate when the catalog has problems.
+type Diagnostics []Diagnostic
+
+func (ds Diagnostics) Error() string {
+	lines := make([]string, len(ds))
+	for i, d := range ds {
+		lines[i] = d.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks every file that was merged into c: unknown keys, invalid
+// or duplicated items, curate, requires and profile entries pointing to
+// unknown IDs and requirement or profile cycles. The
+// returned error, if any, is a Diagnostics.
+func (c *Config) Validate() error {
+	known := map[string]bool{}
+	for _, it := range c.Items {
+		known[it.ID] = true
+	}
+
+	// Only the last curate list and profile definitions win, so earlier
+	// ones are not checked.
+	curateDoc := -1
+	profileDoc := map[string]int{}
+	for i, d := range c.docs {
+		if n := mappingValue(docRoot(d), "curate"); n != nil && len(n.Content) > 0 {
+			curateDoc = i
+		}
+		if n := mappingValue(docRoot(d), "profiles"); n != nil {
+			for j := 0; j+1 < len(n.Content); j += 2 {
+				profileDoc[n.Content[j].Value] = i
+			}
+		}
+	}
+
+	type location struct {
+		file string
+		node *yaml.Node
+	}
+	defined := map[string]location{}
+	// Items defined by each layer's fragments, to catch two fragments
+	// clobbering each other. Later layers and including files may still
+	// override an item on purpose.
+	type layerItem struct{ layer, id string }
+	fragments := map[layerItem]document{}
+
+	var ds Diagnostics
+	for i, d := range c.docs {
+		root := docRoot(d)
+		if root.Kind != yaml.MappingNode {
+			continue
+		}
+		checkKeys(d.name, root, reflect.TypeOf(Config{}), &ds)
+
+		if items := mappingValue(root, "items"); items != nil && items.Kind == yaml.SequenceNode {
+			seen := map[string]*yaml.Node{}
+			for _, n := range items.Content {
+				var it Item
+				if err := n.Decode(&it); err != nil {
+					ds.add(d.name, n, err.Error())
+					continue
+				}
+				if prev, ok := seen[it.ID]; ok && it.ID != "" {
+					ds.add(d.name, mappingValue(n, "id"), fmt.Sprintf("duplicate id %q (first defined at line %d)", it.ID, prev.Line))
+					continue
+				}
+				key := layerItem{d.layer(), it.ID}
+				if prev, ok := fragments[key]; ok && it.ID != "" && !slices.Contains(prev.includedBy, d.name) {
+					ds.add(d.name, mappingValue(n, "id"), fmt.Sprintf("id %q is also defined in %s, included by the same catalog; this definition replaces it", it.ID, prev.name))
+				}
+				fragments[key] = d
+				seen[it.ID] = n
+				defined[it.ID] = location{d.name, n}
+				if it.Disabled {
+					continue
+				}
+				if err := it.Validate(); err != nil {
+					ds.add(d.name, n, err.Error())
+				}
+				if req := mappingValue(n, "requires"); req != nil && req.Kind == yaml.SequenceNode {
+					for _, r := range req.Content {
+						if !known[r.Value] {
+							ds.add(d.name, r, fmt.Sprintf("requires: unknown item %q", r.Value))
+						}
+					}
+				}
+			}
+		}
+
+		if prefer := mappingValue(root, "prefer"); prefer != nil && prefer.Kind == yaml.SequenceNode {
+			for _, n := range prefer.Content {
+				if !slices.Contains(StrategyNames(), n.Value) {
+					ds.add(d.name, n, fmt.Sprintf("prefer: unknown strategy %q", n.Value))
+				}
+			}
+		}
+
+		if profiles := mappingValue(root, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(profiles.Content); j += 2 {
+				name, p := profiles.Content[j], profiles.Content[j+1]
+				if profileDoc[name.Value] != i {
+					continue
+				}
+				for _, key := range []string{"items", "exclude"} {
+					if ids := mappingValue(p, key); ids != nil && ids.Kind == yaml.SequenceNode {
+						for _, n := range ids.Content {
+							if !known[n.Value] {
+								ds.add(d.name, n, fmt.Sprintf("profile %s: unknown item %q", name.Value, n.Value))
+							}
+						}
+					}
+				}
+				if inc := mappingValue(p, "include"); inc != nil && inc.Kind == yaml.SequenceNode {
+					for _, n := range inc.Content {
+						if _, ok := c.LookupProfile(n.Value); !ok {
+							ds.add(d.name, n, fmt.Sprintf("profile %s: unknown profile %q", name.Value, n.Value))
+						}
+					}
+				}
+				if _, err := c.profileIDs(name.Value, nil); err != nil && strings.HasPrefix(err.Error(), "profile cycle") {
+					ds.add(d.name, name, err.Error())
+				}
+			}
+		}
+
+		if curate := mappingValue(root, "curate"); i == curateDoc && curate.Kind == yaml.SequenceNode {
+			for _, n := range curate.Content {
+				if !known[n.Value] {
+					ds.add(d.name, n, fmt.Sprintf("curate: unknown item %q", n.Value))
+				}
+			}
+		}
+	}
+
+	items := map[string]Item{}
+	for _, it := range c.Items {
+		items[it.ID] = it
+	}
+	if cycle := findCycle(items); cycle != nil {
+		loc := defined[cycle[0]]
+		ds.add(loc.file, loc.node, "dependency cycle: "+strings.Join(cycle, " -> "))
+	}
+
+	if len(ds) == 0 {
+		return nil
+	}
+	return ds
+}
+
+func docRoot(d document) *yaml.Node {
+	if d.root.Kind == yaml.DocumentNode && len(d.root.Content) > 0 {
+		return d.root.Content[0]
+	}
+	return d.root
+}
+
+func (ds *Diagnostics) add(file string, n *yaml.Node, msg string) {
+	*ds = append(*ds, Diagnostic{File: file, Line: n.Line, Column: n.Column, Message: msg})
+}
+
+// mappingValue returns the value node for key in mapping node n.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// checkKeys reports mapping keys of n that have no matching yaml field in t.
+func checkKeys(file string, n *yaml.Node, t reflect.Type, ds *Diagnostics) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		switch n.Kind {
+		case yaml.SequenceNode:
+			// A struct written as an ordered list of single-key mappings.
+			for _, e := range n.Content {
+				checkKeys(file, e, t, ds)
+			}
+		case yaml.MappingNode:
+			fields := yamlFields(t)
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k, v := n.Content[i], n.Content[i+1]
+				ft, ok := fields[k.Value]
+				if !ok {
+					ds.add(file, k, fmt.Sprintf("unknown key %q", k.Value))
+					continue
+				}
+				checkKeys(file, v, ft, ds)
+			}
+		}
+	case reflect.Slice:
+		if n.Kind == yaml.SequenceNode {
+			for _, e := range n.Content {
+				checkKeys(file, e, t.Elem(), ds)
+			}
+		}
+	case reflect.Map:
+		if n.Kind == yaml.MappingNode {
+			for i := 1; i < len(n.Content); i += 2 {
+				checkKeys(file, n.Content[i], t.Elem(), ds)
+			}
+		}
+	}
+}
+
+// yamlFields maps the yaml key of every field of struct t to its type.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			for k, v := range yamlFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
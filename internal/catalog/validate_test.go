@@ -0,0 +1,126 @@
+package catalog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCatalog(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const fooItem = "items:\n  - id: foo\n    name: foo\n    strategies:\n      brew: foo\n"
+
+func TestValidateFragmentDuplicates(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string // expected diagnostic, if any
+	}{
+		{
+			name: "two fragments",
+			files: map[string]string{
+				"main.yaml": "include: [a.yaml, b.yaml]\n",
+				"a.yaml":    fooItem,
+				"b.yaml":    fooItem,
+			},
+			want: `b.yaml:2:9: id "foo" is also defined in ` + "%s/a.yaml",
+		},
+		{
+			name: "nested fragments",
+			files: map[string]string{
+				"main.yaml": "include: [a.yaml, b.yaml]\n",
+				"a.yaml":    "include: [c.yaml]\n",
+				"b.yaml":    fooItem,
+				"c.yaml":    fooItem,
+			},
+			want: `b.yaml:2:9: id "foo" is also defined in ` + "%s/c.yaml",
+		},
+		{
+			name: "shared fragment",
+			files: map[string]string{
+				"main.yaml":   "include: [a.yaml, b.yaml]\n",
+				"a.yaml":      "include: [common.yaml]\n",
+				"b.yaml":      "include: [common.yaml]\n",
+				"common.yaml": fooItem,
+			},
+		},
+		{
+			name: "including file overrides its fragment",
+			files: map[string]string{
+				"main.yaml": "include: [a.yaml]\n" + fooItem,
+				"a.yaml":    "include: [b.yaml]\n",
+				"b.yaml":    fooItem,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeCatalog(t, dir, tt.files)
+			c, err := Load(filepath.Join(dir, "main.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = c.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected diagnostics:\n%v", err)
+				}
+				return
+			}
+			var ds Diagnostics
+			if !errors.As(err, &ds) || len(ds) != 1 {
+				t.Fatalf("want one diagnostic, got %v", err)
+			}
+			if want := strings.Replace(tt.want, "%s", dir, 1); !strings.Contains(ds[0].Error(), want) {
+				t.Errorf("diagnostic = %s, want it to contain %s", ds[0], want)
+			}
+		})
+	}
+}
+
+func TestValidateLayerOverride(t *testing.T) {
+	repo := t.TempDir()
+	writeCatalog(t, repo, map[string]string{RepoCatalog: "items:\n  - id: just\n    name: just\n    strategies:\n      brew: just\n"})
+	t.Chdir(repo)
+	t.Setenv(EnvCatalog, "")
+
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("overriding an embedded item from the repo catalog: %v", err)
+	}
+}
+
+func TestLoadUserSkipsRepoCatalog(t *testing.T) {
+	repo := t.TempDir()
+	writeCatalog(t, repo, map[string]string{RepoCatalog: "items:\n  - id: repo-only\n    name: x\n    shell:\n      init:\n        bash: echo PWNED\n    strategies:\n      brew: x\n"})
+	t.Chdir(repo)
+	t.Setenv(EnvCatalog, "")
+
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.ByIDs([]string{"repo-only"})) != 1 {
+		t.Fatal("Load should include the repo catalog")
+	}
+	c, err = LoadUser("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.ByIDs([]string{"repo-only"})) != 0 {
+		t.Error("LoadUser included an item of the repo catalog")
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pirpedro/dev-gadgets/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the download cache",
+	}
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List cached downloads, most recently used first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := cache.Default()
+			refs, err := c.List()
+			if err != nil {
+				return err
+			}
+			var total int64
+			for _, r := range refs {
+				total += r.Size
+				cmd.Printf("%s  %8s  %s  %s\n", r.SHA256[:12], size(r.Size), r.Used.Local().Format("2006-01-02"), r.URL)
+			}
+			cmd.Printf("%d download(s), %s in %s\n", len(refs), size(total), c.Dir)
+			return nil
+		},
+	})
+	cacheCmd.AddCommand(&cobra.Command{
+		Use:   "clean",
+		Short: "Remove every cached download",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := cache.Default()
+			if flagDryRun {
+				cmd.Printf("would remove %s\n", c.Dir)
+				return nil
+			}
+			return c.Clean()
+		},
+	})
+	var olderThan time.Duration
+	gcCmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Drop downloads not used recently and unreferenced files",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			freed, err := cache.Default().GC(olderThan, flagDryRun)
+			if flagDryRun {
+				cmd.Printf("would free %s\n", size(freed))
+			} else {
+				cmd.Printf("freed %s\n", size(freed))
+			}
+			return err
+		},
+	}
+	gcCmd.Flags().DurationVar(&olderThan, "older-than", 30*24*time.Hour, "drop downloads not used for this long")
+	cacheCmd.AddCommand(gcCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func size(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	catalogCmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Inspect the catalog",
+	}
+	catalogCmd.AddCommand(&cobra.Command{
+		Use:   "lint",
+		Short: "Check the catalog files for mistakes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := catalog.Load(flagCatalog)
+			if err != nil {
+				return err
+			}
+			err = cfg.Validate()
+			var ds catalog.Diagnostics
+			if errors.As(err, &ds) {
+				for _, d := range ds {
+					fmt.Fprintln(cmd.OutOrStdout(), d)
+				}
+				return fmt.Errorf("%d problem(s) found", len(ds))
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "OK: %d items from %d file(s)\n", len(cfg.Items), len(cfg.Sources))
+			return nil
+		},
+	})
+	rootCmd.AddCommand(catalogCmd)
+}
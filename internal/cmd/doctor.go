@@ -1,27 +1,32 @@
 package cmd
 
 import (
-    "fmt"
-    "os"
-    "path/filepath"
+	"fmt"
+	"os"
+	"path/filepath"
 
-    "github.com/adrg/xdg"
-    "github.com/spf13/cobra"
+	"github.com/pirpedro/dev-gadgets/internal/install"
+	"github.com/spf13/cobra"
 )
 
 func init() {
-    rootCmd.AddCommand(&cobra.Command{
-        Use:   "doctor",
-        Short: "Check environment and dependencies",
-        RunE: func(cmd *cobra.Command, args []string) error {
-            binDir := filepath.Join(xdg.Home, ".local", "bin")
-            found := false
-            for _, p := range filepath.SplitList(os.Getenv("PATH")) {
-                if p == binDir { found = true; break }
-            }
-            if !found { fmt.Fprintf(cmd.OutOrStdout(), "Hint: add %s to your PATH\n", binDir) }
-            fmt.Fprintln(cmd.OutOrStdout(), "OK")
-            return nil
-        },
-    })
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "doctor",
+		Short: "Check environment and dependencies",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			binDir := install.BinDir()
+			found := false
+			for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+				if p == binDir {
+					found = true
+					break
+				}
+			}
+			if !found {
+				fmt.Fprintf(cmd.OutOrStdout(), "Hint: add %s to your PATH, e.g. with `dev-gadgets shell-init bash --write`\n", binDir)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "OK")
+			return nil
+		},
+	})
 }
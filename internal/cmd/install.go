@@ -2,12 +2,19 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/pirpedro/dev-gadgets/internal/catalog"
 	"github.com/pirpedro/dev-gadgets/internal/install"
+	"github.com/pirpedro/dev-gadgets/internal/lock"
+	"github.com/pirpedro/dev-gadgets/internal/toolversions"
 	"github.com/pirpedro/dev-gadgets/internal/ui"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
@@ -17,6 +24,12 @@ var (
 	flagAll         bool
 	flagInteractive bool
 	flagOnly        string
+	flagProfile     string
+	flagStrategy    []string
+	flagTag         []string
+	flagExcludeTag  []string
+	flagFrozen      bool
+	flagToolVersion string
 )
 
 func init() {
@@ -25,22 +38,71 @@ func init() {
 		Short: "Install curated tools and add-ons",
 		RunE:  runInstall,
 	}
-	cmd.Flags().BoolVar(&flagAll, "all", false, "install curated defaults")
+	cmd.Flags().BoolVar(&flagAll, "all", false, "install curated defaults (the default profile)")
+	cmd.Flags().StringVar(&flagProfile, "profile", "", "install the items of a named profile")
 	cmd.Flags().BoolVar(&flagInteractive, "interactive", false, "interactive TUI selection")
 	cmd.Flags().StringVar(&flagOnly, "only", "", "comma-separated subset of item IDs")
+	cmd.Flags().StringSliceVar(&flagStrategy, "strategy", nil, "only try these strategies, in this order (e.g. apt,release)")
+	cmd.Flags().StringSliceVar(&flagTag, "tag", nil, "only items with any of these tags")
+	cmd.Flags().StringSliceVar(&flagExcludeTag, "exclude-tag", nil, "skip items with any of these tags")
+	cmd.Flags().BoolVar(&flagFrozen, "frozen", false, "install exactly what "+lock.File+" records, or fail")
+	cmd.Flags().StringVar(&flagToolVersion, "tool-versions", "", "read pins from, or write them to, the repo's mise.toml or .tool-versions (read|write)")
 	rootCmd.AddCommand(cmd)
 }
 
 func runInstall(cmd *cobra.Command, args []string) error {
-	cfg, err := catalog.Load()
+	cfg, err := catalog.Load(flagCatalog)
 	if err != nil {
 		return err
 	}
+	for _, name := range flagStrategy {
+		if !slices.Contains(catalog.StrategyNames(), name) {
+			return fmt.Errorf("unknown strategy %q (known: %s)", name, strings.Join(catalog.StrategyNames(), ", "))
+		}
+	}
+	opts := install.Options{AssumeYes: flagYes, Prefer: cfg.Prefer, Strategies: flagStrategy, Frozen: flagFrozen}
+
+	// Outside a repository there is no team to share a lockfile with.
+	repoDir, inRepo := catalog.FindRepoDir()
+	lockPath := filepath.Join(repoDir, lock.File)
+	lk, err := lock.Read(lockPath)
+	switch {
+	case !inRepo && flagFrozen:
+		return fmt.Errorf("--frozen: not inside a repository (no .git or %s found)", catalog.RepoCatalog)
+	case !inRepo:
+		lk = &lock.Lock{}
+	case errors.Is(err, fs.ErrNotExist):
+		if flagFrozen {
+			return fmt.Errorf("--frozen: %s not found", lockPath)
+		}
+		lk = &lock.Lock{}
+	case err != nil:
+		return fmt.Errorf("%s: %v", lockPath, err)
+	}
+	opts.Lock = lk
+
+	pinPath := toolversions.Find(catalog.RepoDir())
+	var pins map[string]string
+	switch flagToolVersion {
+	case "":
+	case "read", "write":
+		if pins, err = toolversions.Read(pinPath); err != nil {
+			return fmt.Errorf("%s: %v", pinPath, err)
+		}
+	default:
+		return fmt.Errorf("--tool-versions must be read or write, not %q", flagToolVersion)
+	}
 
 	var toInstall []catalog.Item
 	switch {
+	case flagProfile != "":
+		if toInstall, err = cfg.ProfileItems(flagProfile); err != nil {
+			return err
+		}
 	case flagAll:
-		toInstall = cfg.Curated()
+		if toInstall, err = cfg.ProfileItems(catalog.DefaultProfile); err != nil {
+			return err
+		}
 	case flagOnly != "":
 		ids := strings.Split(flagOnly, ",")
 		toInstall = cfg.ByIDs(ids)
@@ -49,11 +111,16 @@ func runInstall(cmd *cobra.Command, args []string) error {
 		importUI := func() ([]catalog.Item, error) {
 			// Constrói lista de SelectItem
 			var items []ui.SelectItem
-			for _, it := range cfg.Items {
+			for _, it := range catalog.FilterTags(cfg.Items, flagTag, flagExcludeTag) {
+				group := "other"
+				if len(it.Tags) > 0 {
+					group = it.Tags[0]
+				}
 				items = append(items, ui.SelectItem{
 					ID:        it.ID,
 					Name:      it.Name,
 					Desc:      it.Description,
+					Group:     group,
 					Installed: ui.IsInstalled(it),
 				})
 			}
@@ -79,21 +146,219 @@ func runInstall(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		toInstall = selected
+	case flagFrozen:
+		// Reproduce the locked set itself.
+		for _, e := range lk.Items {
+			items := cfg.ByIDs([]string{e.ID})
+			if len(items) == 0 {
+				return fmt.Errorf("--frozen: %s is locked but not in the catalog", e.ID)
+			}
+			toInstall = append(toInstall, items[0])
+		}
+	case len(flagTag) > 0:
+		toInstall = cfg.Items
 	default:
-		toInstall = cfg.Curated()
+		if toInstall, err = cfg.ProfileItems(catalog.DefaultProfile); err != nil {
+			return err
+		}
+	}
+	toInstall = catalog.FilterTags(toInstall, flagTag, flagExcludeTag)
+
+	stages, err := cfg.Plan(toInstall)
+	if err != nil {
+		return err
+	}
+	if flagToolVersion == "read" {
+		// The repo's mise/asdf pins win over the catalog's versions.
+		for _, stage := range stages {
+			for i, it := range stage {
+				if v, ok := pins[it.Strategy.Mise]; ok && it.Strategy.Mise != "" {
+					stage[i].Version = v
+				}
+			}
+		}
 	}
 
 	if flagDryRun {
-		for _, it := range toInstall {
-			fmt.Fprintf(cmd.OutOrStdout(), "PLAN: %s\n", it.ID)
+		for _, stage := range stages {
+			for _, it := range stage {
+				fmt.Fprintf(cmd.OutOrStdout(), "PLAN: %s%s\n", it.ID, requiredBy(it.ID, toInstall, stages))
+				for _, h := range it.PostInstall {
+					when := ""
+					if h.When != "" {
+						when = " (when " + h.When + ")"
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "  post_install: %s%s\n", h.Run, when)
+				}
+			}
+		}
+		return nil
+	}
+
+	// Stages run in order; the items of a stage are independent of each other.
+	// A failed item only stops the items that require it, directly or not;
+	// the install of its siblings runs to the end.
+	var outcomes []outcome
+	var installErrs []error
+	failed := map[string]bool{}
+	for _, stage := range stages {
+		stageOutcomes := make([]outcome, len(stage))
+		var g errgroup.Group
+		for i, it := range stage {
+			blocked := slices.DeleteFunc(slices.Clone(it.Requires), func(dep string) bool { return !failed[dep] })
+			if len(blocked) > 0 {
+				stageOutcomes[i] = outcome{Result: install.Result{Item: it}, blockedBy: blocked}
+				continue
+			}
+			g.Go(func() error {
+				res, err := install.Install(context.Background(), it, opts)
+				stageOutcomes[i] = outcome{Result: res, err: err, done: true}
+				return nil
+			})
+		}
+		g.Wait()
+		for _, o := range stageOutcomes {
+			if !o.done || o.err != nil {
+				failed[o.Item.ID] = true
+			}
+			if o.err != nil {
+				installErrs = append(installErrs, o.err)
+			}
+		}
+		outcomes = append(outcomes, stageOutcomes...)
+	}
+	installErr := errors.Join(installErrs...)
+
+	// Hooks may prompt, so they run one at a time once everything is in place.
+	var hookErrs []error
+	for i, o := range outcomes {
+		if !o.done || o.err != nil {
+			continue
+		}
+		if err := install.RunHooks(context.Background(), o.Result); err != nil {
+			outcomes[i].hookErr = err
+			hookErrs = append(hookErrs, err)
+		}
+	}
+
+	printSummary(cmd.OutOrStdout(), outcomes)
+	if !flagFrozen && inRepo {
+		if err := updateLock(cmd.ErrOrStderr(), lk, outcomes, lockPath, opts); err != nil {
+			return err
+		}
+	}
+	if flagToolVersion == "write" {
+		if err := writePins(pinPath, outcomes); err != nil {
+			return err
+		}
+	}
+	if installErr != nil {
+		return installErr
+	}
+	return errors.Join(hookErrs...)
+}
+
+// updateLock records the successful outcomes and writes the lockfile.
+// Items that were already present are locked with the strategy that would
+// install them here; those no strategy can install are left out, since a
+// frozen install elsewhere could not reproduce them.
+func updateLock(w io.Writer, lk *lock.Lock, outcomes []outcome, path string, opts install.Options) error {
+	changed := false
+	for _, o := range outcomes {
+		if !o.done || o.err != nil {
+			continue
+		}
+		e := lock.Entry{ID: o.Item.ID, Strategy: o.Strategy, Version: o.Version, Source: o.Source, SHA256: o.SHA256}
+		if o.AlreadyInstalled {
+			// Keep how it was locked as long as the version still matches.
+			if prev, ok := lk.Get(o.Item.ID); ok && prev.Strategy != "" && prev.Version == o.Version {
+				continue
+			}
+			name, ok := install.Resolve(o.Item, opts)
+			if !ok {
+				fmt.Fprintf(w, "[dev-gadgets] not locking %s: it is already installed, but none of its strategies is available here to reproduce it\n", o.Item.ID)
+				continue
+			}
+			e.Strategy = name
+		}
+		lk.Set(e)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return lk.Write(path)
+}
+
+// writePins records the versions of the mise items that are in place.
+func writePins(path string, outcomes []outcome) error {
+	pins := map[string]string{}
+	for _, o := range outcomes {
+		if o.done && o.err == nil && o.Item.Strategy.Mise != "" && o.Version != "" {
+			pins[o.Item.Strategy.Mise] = o.Version
 		}
+	}
+	if len(pins) == 0 {
 		return nil
 	}
+	return toolversions.Write(path, pins)
+}
+
+// outcome is the result of one item of the install plan.
+type outcome struct {
+	install.Result
+	err     error
+	hookErr error
+	done    bool // false when the item was not attempted
+
+	blockedBy []string // failed requirements that kept it from being attempted
+}
+
+func printSummary(w io.Writer, outcomes []outcome) {
+	fmt.Fprintln(w, "\nSummary:")
+	var notes []string
+	for _, o := range outcomes {
+		status, detail := "installed", o.Strategy
+		switch {
+		case !o.done:
+			status, detail = "skipped", "needs "+strings.Join(o.blockedBy, ", ")+", which was not installed"
+		case o.err != nil:
+			status, detail = "failed", o.err.Error()
+		case o.AlreadyInstalled:
+			status, detail = "present", "already installed"
+		}
+		if o.err == nil && o.Version != "" {
+			detail += ", " + o.Version
+		}
+		if o.hookErr != nil {
+			detail += "; " + o.hookErr.Error()
+		}
+		fmt.Fprintf(w, "  %-10s %-20s %s\n", status, o.Item.ID, detail)
+		if o.done && o.err == nil && o.Item.Notes != "" {
+			notes = append(notes, fmt.Sprintf("  %s: %s", o.Item.ID, strings.TrimSpace(o.Item.Notes)))
+		}
+	}
+	if len(notes) > 0 {
+		fmt.Fprintln(w, "\nNotes:")
+		for _, n := range notes {
+			fmt.Fprintln(w, n)
+		}
+	}
+}
 
-	g, ctx := errgroup.WithContext(context.Background())
-	for _, it := range toInstall {
-		it := it
-		g.Go(func() error { return install.Install(ctx, it, install.Options{AssumeYes: flagYes}) })
+// requiredBy annotates items that were not selected but pulled in as a
+// dependency of another planned item.
+func requiredBy(id string, selected []catalog.Item, stages [][]catalog.Item) string {
+	if slices.ContainsFunc(selected, func(it catalog.Item) bool { return it.ID == id }) {
+		return ""
+	}
+	var by []string
+	for _, stage := range stages {
+		for _, it := range stage {
+			if slices.Contains(it.Requires, id) {
+				by = append(by, it.ID)
+			}
+		}
 	}
-	return g.Wait()
+	return fmt.Sprintf(" (required by %s)", strings.Join(by, ", "))
 }
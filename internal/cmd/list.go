@@ -1,27 +1,35 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/pirpedro/dev-gadgets/internal/catalog"
 	"github.com/spf13/cobra"
 )
 
 func init() {
-	rootCmd.AddCommand(&cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List catalog items",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := catalog.Load()
+			cfg, err := catalog.Load(flagCatalog)
 			if err != nil {
 				return err
 			}
-			for _, it := range cfg.Items {
+			for _, it := range catalog.FilterTags(cfg.Items, flagTag, flagExcludeTag) {
 				desc := it.Description
 				if desc == "" {
 					desc = "(no description)"
 				}
+				if len(it.Tags) > 0 {
+					desc += " [" + strings.Join(it.Tags, ", ") + "]"
+				}
 				cmd.Printf("%-20s %s\n", it.ID, desc)
 			}
 			return nil
 		},
-	})
+	}
+	cmd.Flags().StringSliceVar(&flagTag, "tag", nil, "only items with any of these tags")
+	cmd.Flags().StringSliceVar(&flagExcludeTag, "exclude-tag", nil, "skip items with any of these tags")
+	rootCmd.AddCommand(cmd)
 }
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/install"
+	"github.com/pirpedro/dev-gadgets/internal/lock"
+	"github.com/pirpedro/dev-gadgets/internal/semver"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	lockCmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Inspect " + lock.File,
+	}
+	lockCmd.AddCommand(&cobra.Command{
+		Use:   "check",
+		Short: "Report drift between the lockfile and this machine",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := catalog.Load(flagCatalog)
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(catalog.RepoDir(), lock.File)
+			lk, err := lock.Read(path)
+			if err != nil {
+				return err
+			}
+
+			drift := 0
+			for _, e := range lk.Items {
+				status, detail := "ok", e.Version
+				items := cfg.ByIDs([]string{e.ID})
+				switch {
+				case len(items) == 0:
+					status, detail = "unknown", "not in the catalog"
+				case items[0].Verify == "":
+					status, detail = "unchecked", "item has no verify command"
+				default:
+					found, ok := install.InstalledVersion(items[0])
+					switch {
+					case !ok:
+						status, detail = "missing", "locked "+e.Version
+					case !sameVersion(found, e.Version):
+						status, detail = "drift", fmt.Sprintf("locked %s, found %s", e.Version, found)
+					}
+				}
+				if status != "ok" && status != "unchecked" {
+					drift++
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%-10s %-20s %s\n", status, e.ID, detail)
+			}
+			if drift > 0 {
+				return fmt.Errorf("%d item(s) differ from %s", drift, path)
+			}
+			return nil
+		},
+	})
+	rootCmd.AddCommand(lockCmd)
+}
+
+func sameVersion(a, b string) bool {
+	if a == b {
+		return true
+	}
+	va, errA := semver.Parse(a)
+	vb, errB := semver.Parse(b)
+	return errA == nil && errB == nil && semver.Compare(va, vb) == 0
+}
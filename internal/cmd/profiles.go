@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "profiles",
+		Short: "List install profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := catalog.Load(flagCatalog)
+			if err != nil {
+				return err
+			}
+			for _, name := range cfg.ProfileNames() {
+				p, _ := cfg.LookupProfile(name)
+				desc := p.Description
+				if desc == "" {
+					desc = "(no description)"
+				}
+				cmd.Printf("%-20s %s\n", name, desc)
+				items, err := cfg.ProfileItems(name)
+				if err != nil {
+					cmd.Printf("%-20s error: %v\n", "", err)
+					continue
+				}
+				ids := make([]string, len(items))
+				for i, it := range items {
+					ids[i] = it.ID
+				}
+				cmd.Printf("%-20s %s\n", "", strings.Join(ids, ", "))
+			}
+			return nil
+		},
+	})
+}
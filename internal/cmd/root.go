@@ -8,9 +8,10 @@ import (
 )
 
 var (
-	flagYes    bool
-	flagDryRun bool
-	version    = "dev"
+	flagYes     bool
+	flagDryRun  bool
+	flagCatalog string
+	version     = "dev"
 )
 
 var rootCmd = &cobra.Command{
@@ -30,4 +31,5 @@ func init() {
 	rootCmd.SilenceUsage = true
 	rootCmd.PersistentFlags().BoolVar(&flagYes, "yes", false, "assume yes to confirmations")
 	rootCmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "print plan only, do not execute")
+	rootCmd.PersistentFlags().StringVar(&flagCatalog, "catalog", "", "catalog file to use instead of the layered defaults (env DEV_GADGETS_CATALOG)")
 }
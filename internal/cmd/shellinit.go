@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/install"
+	"github.com/pirpedro/dev-gadgets/internal/shell"
+	"github.com/spf13/cobra"
+)
+
+var flagWriteRC bool
+
+func init() {
+	cmd := &cobra.Command{
+		Use:       "shell-init bash|zsh|fish",
+		Short:     "Print shell setup (PATH, env, completions) for installed items",
+		Example:   `  eval "$(dev-gadgets shell-init bash)"` + "\n" + `  dev-gadgets shell-init fish | source`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: catalog.Shells,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// The snippet is evaluated by every new shell, so a repo-local
+			// catalog (which anyone can commit) must not contribute to it.
+			cfg, err := catalog.LoadUser(flagCatalog)
+			if err != nil {
+				return err
+			}
+			// This runs on every shell startup, so items are only looked up,
+			// not verified.
+			var items []catalog.Item
+			for _, it := range cfg.Items {
+				if it.Shell != nil && install.Present(it) {
+					items = append(items, it)
+				}
+			}
+			snippet, err := shell.Snippet(args[0], install.BinDir(), items)
+			if err != nil {
+				return err
+			}
+			if !flagWriteRC {
+				fmt.Fprint(cmd.OutOrStdout(), snippet)
+				return nil
+			}
+
+			rc := shell.RCFile(args[0])
+			if flagDryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "PLAN: update managed block in %s\n%s", rc, snippet)
+				return nil
+			}
+			if err := shell.WriteBlock(rc, snippet); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Updated %s; open a new shell to load it.\n", rc)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&flagWriteRC, "write", false, "write the snippet into a managed block of the shell rc file")
+	rootCmd.AddCommand(cmd)
+}
@@ -0,0 +1,284 @@
+package install
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type archiveKind int
+
+const (
+	kindBinary archiveKind = iota
+	kindZip
+	kindTar
+	kindTarGz
+	kindTarXz
+	kindTarBz2
+)
+
+// detectArchive sniffs the magic bytes of file, falling back to the
+// extension of name, the file's name as downloaded (cached files have none).
+func detectArchive(file, name string) (archiveKind, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return kindBinary, err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return kindZip, nil
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return kindTarGz, nil
+	case bytes.HasPrefix(head, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return kindTarXz, nil
+	case bytes.HasPrefix(head, []byte("BZh")):
+		return kindTarBz2, nil
+	case len(head) > 262 && string(head[257:262]) == "ustar":
+		return kindTar, nil
+	}
+
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return kindZip, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return kindTarGz, nil
+	case strings.HasSuffix(name, ".tar.xz"), strings.HasSuffix(name, ".txz"):
+		return kindTarXz, nil
+	case strings.HasSuffix(name, ".tar.bz2"), strings.HasSuffix(name, ".tbz"):
+		return kindTarBz2, nil
+	case strings.HasSuffix(name, ".tar"):
+		return kindTar, nil
+	}
+	return kindBinary, nil
+}
+
+// payloadFile is one file to pull out of a release archive.
+type payloadFile struct {
+	member string // path in the archive; without a slash, any file of that name
+	dest   string
+	mode   fs.FileMode
+}
+
+func (f payloadFile) matches(name string) bool {
+	name = strings.TrimPrefix(path.Clean(name), "./")
+	if !strings.Contains(f.member, "/") {
+		return path.Base(name) == f.member
+	}
+	member := strings.TrimPrefix(path.Clean(f.member), "./")
+	return name == member || strings.HasSuffix(name, "/"+member)
+}
+
+// extractFiles writes each of files out of archive, downloaded as name, to
+// its destination. Everything is extracted to a staging dir first, so an
+// archive lacking one of the files installs none of them.
+func extractFiles(archive, name string, files []payloadFile) error {
+	stage, err := os.MkdirTemp("", "dev-gadgets-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stage)
+	staged := slices.Clone(files)
+	for i := range staged {
+		staged[i].dest = filepath.Join(stage, strconv.Itoa(i))
+	}
+	if err := extract(archive, name, staged); err != nil {
+		return err
+	}
+
+	for i, f := range files {
+		r, err := os.Open(staged[i].dest)
+		if err != nil {
+			return err
+		}
+		err = writeFile(f.dest, r, f.mode)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extract writes files out of archive. A bare binary can only provide a
+// single file.
+func extract(archive, name string, files []payloadFile) error {
+	kind, err := detectArchive(archive, name)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case kindBinary:
+		if len(files) != 1 {
+			return fmt.Errorf("%s is a single file, not an archive", name)
+		}
+		f, err := os.Open(archive)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return writeFile(files[0].dest, f, files[0].mode)
+	case kindZip:
+		return extractZip(archive, files)
+	case kindTarXz:
+		// No xz reader in the standard library; lean on the system xz.
+		cmd := exec.Command("xz", "-dc", archive)
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("xz not available: %v", err)
+		}
+		err = extractTar(out, files)
+		io.Copy(io.Discard, out)
+		if werr := cmd.Wait(); err == nil && werr != nil {
+			err = fmt.Errorf("xz failed: %v", werr)
+		}
+		return err
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	switch kind {
+	case kindTarGz:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case kindTarBz2:
+		r = bzip2.NewReader(f)
+	}
+	return extractTar(r, files)
+}
+
+func extractZip(archive string, files []payloadFile) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	done := make([]bool, len(files))
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		for i, f := range files {
+			if done[i] || !f.matches(zf.Name) {
+				continue
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				return err
+			}
+			err = writeFile(f.dest, rc, f.mode)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+			done[i] = true
+		}
+	}
+	return missing(files, done)
+}
+
+func extractTar(r io.Reader, files []payloadFile) error {
+	tr := tar.NewReader(r)
+	done := make([]bool, len(files))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return missing(files, done)
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		// A member may be wanted twice (e.g. as bin and completion); read it once.
+		var data []byte
+		for i, f := range files {
+			if done[i] || !f.matches(hdr.Name) {
+				continue
+			}
+			if data == nil {
+				if data, err = io.ReadAll(tr); err != nil {
+					return err
+				}
+			}
+			if err := writeFile(f.dest, bytes.NewReader(data), f.mode); err != nil {
+				return err
+			}
+			done[i] = true
+		}
+	}
+}
+
+func missing(files []payloadFile, done []bool) error {
+	var names []string
+	for i, f := range files {
+		if !done[i] {
+			names = append(names, f.member)
+		}
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("%s not found in archive", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// writeExecutable writes r to dest atomically with mode 0755.
+func writeExecutable(dest string, r io.Reader) error {
+	return writeFile(dest, r, 0o755)
+}
+
+// writeFile writes r to dest atomically with mode, creating its directory.
+func writeFile(dest string, r io.Reader, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
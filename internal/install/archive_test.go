@@ -0,0 +1,102 @@
+package install
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectArchive(t *testing.T) {
+	v7tar := v7Tar(t, "tool", "tool")
+	tests := []struct {
+		name    string
+		content []byte
+		want    archiveKind
+	}{
+		{"tool.zip", []byte("PK\x03\x04rest"), kindZip},
+		{"tool", []byte{0x1f, 0x8b, 8, 0}, kindTarGz},
+		{"tool", []byte("BZh91AY"), kindTarBz2},
+		{"tool.tar", v7tar, kindTar},
+		{"tool", v7tar, kindBinary},
+		{"tool.tar.bz2", []byte("??"), kindTarBz2},
+		{"tool.tgz", []byte("??"), kindTarGz},
+		{"tool", []byte("#!/bin/sh\n"), kindBinary},
+	}
+	for _, tt := range tests {
+		// Stored like the download cache does: no extension on disk.
+		file := filepath.Join(t.TempDir(), "0123abcd")
+		if err := os.WriteFile(file, tt.content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := detectArchive(file, tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("detectArchive(%s, %q...) = %d, want %d", tt.name, tt.content[:2], got, tt.want)
+		}
+	}
+}
+
+func TestExtractFilesByName(t *testing.T) {
+	b := v7Tar(t, "dist/tool", "tool")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "blob")
+	if err := os.WriteFile(file, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "bin", "tool")
+	if err := extractFiles(file, "tool.tar", []payloadFile{{member: "tool", dest: dest, mode: 0o755}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(dest); err != nil || string(got) != "tool" {
+		t.Errorf("extracted %q, %v", got, err)
+	}
+}
+
+func TestExtractFilesMissingInstallsNothing(t *testing.T) {
+	b := v7Tar(t, "dist/tool", "tool")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "blob")
+	if err := os.WriteFile(file, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+	files := []payloadFile{
+		{member: "tool", dest: filepath.Join(out, "bin", "tool"), mode: 0o755},
+		{member: "completions/tool.bash", dest: filepath.Join(out, "completions", "tool"), mode: 0o644},
+	}
+	err := extractFiles(file, "tool.tar", files)
+	if err == nil || !strings.Contains(err.Error(), "completions/tool.bash not found") {
+		t.Fatalf("err = %v, want the missing completion", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("%s was created despite the missing file", out)
+	}
+}
+
+// v7Tar builds a pre-POSIX tar holding one file: it has no magic bytes, so
+// only its name tells it apart from a bare binary.
+func v7Tar(t *testing.T, name, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Format: tar.FormatGNU}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte(content))
+	tw.Close()
+	b := buf.Bytes()
+	copy(b[257:265], make([]byte, 8)) // drop the magic and version
+	copy(b[148:156], "        ")
+	sum := 0
+	for _, c := range b[:512] {
+		sum += int(c)
+	}
+	copy(b[148:156], fmt.Sprintf("%06o\x00 ", sum))
+	return b
+}
@@ -0,0 +1,82 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/semver"
+)
+
+// runContainer pulls the image of it and writes a shim into the user bin
+// dir that runs the tool in a throwaway container.
+func runContainer(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+	c := it.Strategy.Container
+	image := containerImage(it)
+	a := artifact{Source: image}
+
+	engine := "docker"
+	if !has(engine) {
+		engine = "podman"
+	}
+	cmd := exec.CommandContext(ctx, engine, "pull", image)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return a, fmt.Errorf("%s pull failed: %v\n%s", engine, err, out)
+	}
+
+	bin := c.Bin
+	if bin == "" {
+		bin = it.ID
+	}
+	binDir := getXdgBinDir()
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return a, err
+	}
+	shim := containerShim(it.ID, image, c.Entrypoint)
+	return a, writeExecutable(filepath.Join(binDir, bin), strings.NewReader(shim))
+}
+
+// containerImage tags the image with the pinned version when it has no tag
+// or digest of its own.
+func containerImage(it catalog.Item) string {
+	image := it.Strategy.Container.Image
+	name := image[strings.LastIndex(image, "/")+1:]
+	if v, ok := semver.Exact(it.Version); ok && !strings.ContainsAny(name, ":@") {
+		return image + ":" + v
+	}
+	return image
+}
+
+// containerShim picks docker or podman when it runs, so the shim keeps
+// working if the engine changes; DEV_GADGETS_CONTAINER_ENGINE forces one.
+func containerShim(id, image, entrypoint string) string {
+	run := "--rm -i $tty -v \"$PWD:$PWD\" -w \"$PWD\""
+	if entrypoint != "" {
+		run += " --entrypoint " + shellQuote(entrypoint)
+	}
+	return fmt.Sprintf(`#!/bin/sh
+# Generated by dev-gadgets for %s: runs %s in a container.
+engine=${DEV_GADGETS_CONTAINER_ENGINE:-}
+if [ -z "$engine" ]; then
+  if command -v docker >/dev/null 2>&1; then engine=docker
+  elif command -v podman >/dev/null 2>&1; then engine=podman
+  else echo "%s: docker or podman is required" >&2; exit 127
+  fi
+fi
+tty=
+if [ -t 0 ] && [ -t 1 ]; then tty=-t; fi
+case "$engine" in
+  *podman) user="--userns=keep-id" ;;
+  *) user="--user $(id -u):$(id -g)" ;;
+esac
+exec "$engine" run %s $user %s "$@"
+`, id, image, id, run, shellQuote(image))
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
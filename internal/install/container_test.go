@@ -0,0 +1,107 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+// fakeEngine puts docker and podman scripts first on PATH that append
+// their arguments, one per line, to the returned log.
+func fakeEngine(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args.log")
+	for _, engine := range []string{"docker", "podman"} {
+		script := fmt.Sprintf("#!/bin/sh\necho \"== %s\" >> %q\nfor a in \"$@\"; do echo \"$a\" >> %q; done\n", engine, log, log)
+		if err := os.WriteFile(filepath.Join(dir, engine), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func readArgs(t *testing.T, log string) []string {
+	t.Helper()
+	b, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(log)
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func TestRunContainer(t *testing.T) {
+	log := fakeEngine(t)
+	binDir := t.TempDir()
+	t.Setenv("XDG_BIN_HOME", binDir)
+	t.Setenv("DEV_GADGETS_CONTAINER_ENGINE", "")
+
+	it := catalog.Item{
+		ID:      "golangci-lint",
+		Version: "2.1.0",
+		Strategy: catalog.Strategy{Container: &catalog.Container{
+			Image:      "docker.io/golangci/golangci-lint",
+			Entrypoint: "golangci-lint",
+			Bin:        "lint",
+		}},
+	}
+	a, err := runContainer(context.Background(), it, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	image := "docker.io/golangci/golangci-lint:2.1.0"
+	if a.Source != image {
+		t.Errorf("source = %s, want %s", a.Source, image)
+	}
+	if got, want := readArgs(t, log), []string{"== docker", "pull", image}; !slices.Equal(got, want) {
+		t.Errorf("pull args = %q, want %q", got, want)
+	}
+
+	pwd := t.TempDir()
+	shim := filepath.Join(binDir, "lint")
+	run := func(engine string) []string {
+		t.Helper()
+		cmd := exec.Command(shim, "run", "it's ./...")
+		cmd.Dir = pwd
+		cmd.Env = append(os.Environ(), "PWD="+pwd, "DEV_GADGETS_CONTAINER_ENGINE="+engine)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("shim: %v\n%s", err, out)
+		}
+		return readArgs(t, log)
+	}
+
+	user := fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	want := []string{"== docker", "run", "--rm", "-i", "-v", pwd + ":" + pwd, "-w", pwd, "--entrypoint", "golangci-lint", "--user", user, image, "run", "it's ./..."}
+	if got := run(""); !slices.Equal(got, want) {
+		t.Errorf("docker run args =\n%q\nwant\n%q", got, want)
+	}
+	want = []string{"== podman", "run", "--rm", "-i", "-v", pwd + ":" + pwd, "-w", pwd, "--entrypoint", "golangci-lint", "--userns=keep-id", image, "run", "it's ./..."}
+	if got := run("podman"); !slices.Equal(got, want) {
+		t.Errorf("podman run args =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestContainerImage(t *testing.T) {
+	tests := []struct{ image, version, want string }{
+		{"docker.io/golangci/golangci-lint", "2.1.0", "docker.io/golangci/golangci-lint:2.1.0"},
+		{"docker.io/golangci/golangci-lint", ">=2", "docker.io/golangci/golangci-lint"},
+		{"docker.io/golangci/golangci-lint:v2.1.0", "2.1.0", "docker.io/golangci/golangci-lint:v2.1.0"},
+		{"localhost:5000/lint", "1.0.0", "localhost:5000/lint:1.0.0"},
+		{"ghcr.io/o/lint@sha256:abc", "1.0.0", "ghcr.io/o/lint@sha256:abc"},
+	}
+	for _, tt := range tests {
+		it := catalog.Item{ID: "x", Version: tt.version, Strategy: catalog.Strategy{Container: &catalog.Container{Image: tt.image}}}
+		if got := containerImage(it); got != tt.want {
+			t.Errorf("containerImage(%s, %s) = %s, want %s", tt.image, tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package install
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/semver"
+)
+
+// EnvGitHubAPI overrides the GitHub API base URL, e.g. for GitHub Enterprise.
+const EnvGitHubAPI = "DEV_GADGETS_GITHUB_API"
+
+// Usual spellings of Go's OS and arch names in release asset names.
+var (
+	githubOS = map[string]string{
+		"linux":   `(?i)linux`,
+		"darwin":  `(?i)(darwin|macos|apple|osx)`,
+		"windows": `(?i)(windows|win64)`,
+		"freebsd": `(?i)freebsd`,
+	}
+	githubArch = map[string]string{
+		"amd64": `(?i)(x86_64|amd64|x64|intel_64)`,
+		"arm64": `(?i)(arm64|aarch64|arm_64)`,
+		"386":   `(?i)(i386|i686|x86_32|386)`,
+		"arm":   `(?i)(armv7|armv6|armhf)`,
+	}
+	// Checksums, signatures, SBOMs and OS packages are never the binary.
+	archiveName = regexp.MustCompile(`(?i)\.(zip|tar|tar\.gz|tgz|tar\.xz|txz|tar\.bz2|tbz)$`)
+	githubSkip  = regexp.MustCompile(`(?i)\.(sha256|sha512|md5|sig|asc|pem|cert|minisig|sbom|json|jsonl|txt|deb|rpm|apk|msi|pkg|dmg)$|checksums`)
+)
+
+type githubRelease struct {
+	TagName string        `json:"tag_name"`
+	Assets  []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// runGitHub resolves the release of it through the GitHub API and installs
+// the asset matching the current platform.
+func runGitHub(ctx context.Context, it catalog.Item, opts Options) (artifact, error) {
+	g := it.Strategy.GitHub
+	rel, err := githubLookup(ctx, g.Repo, it.Version)
+	if err != nil {
+		return artifact{}, fmt.Errorf("github release lookup failed for %s: %v", it.ID, err)
+	}
+	asset, err := pickAsset(g, rel.Assets, currentPlatform())
+	if err != nil {
+		return artifact{}, fmt.Errorf("%s %s: %v", g.Repo, rel.TagName, err)
+	}
+	files, err := payloadFiles(it, g.Bin, g.Payload)
+	if err != nil {
+		return artifact{Source: asset.URL}, err
+	}
+	find := func(match func(string) bool) (githubAsset, bool) {
+		i := slices.IndexFunc(rel.Assets, func(a githubAsset) bool { return match(a.Name) })
+		if i < 0 {
+			return githubAsset{}, false
+		}
+		return rel.Assets[i], true
+	}
+	var sums githubAsset
+	if g.Checksums != "" {
+		re, err := regexp.Compile(g.Checksums)
+		if err != nil {
+			return artifact{Source: asset.URL}, fmt.Errorf("%s: checksums: %v", it.ID, err)
+		}
+		var ok bool
+		if sums, ok = find(re.MatchString); !ok {
+			return artifact{Source: asset.URL}, fmt.Errorf("%s %s: no checksums asset matches %s", g.Repo, rel.TagName, g.Checksums)
+		}
+	}
+	var sig githubAsset
+	if g.Signature != nil {
+		// The signature covers the checksums file when there is one.
+		match := func(name string) bool { return name == cmp.Or(sums.Name, asset.Name)+signatureExt(g.Signature) }
+		if g.Signature.URL != "" {
+			re, err := regexp.Compile(g.Signature.URL)
+			if err != nil {
+				return artifact{Source: asset.URL}, fmt.Errorf("%s: signature url: %v", it.ID, err)
+			}
+			match = re.MatchString
+		}
+		var ok bool
+		if sig, ok = find(match); !ok {
+			return artifact{Source: asset.URL}, fmt.Errorf("%s %s: no signature asset for %s", g.Repo, rel.TagName, cmp.Or(sums.Name, asset.Name))
+		}
+	}
+	t, err := resolveTrust(ctx, asset.Name, g.SHA256, sums.URL, g.Signature, sig.URL)
+	if err != nil {
+		return artifact{Source: asset.URL}, fmt.Errorf("%s: %w", it.ID, err)
+	}
+	return fetchPayload(ctx, it, asset.URL, files, t, opts)
+}
+
+// githubLookup fetches the latest release of repo, or the one tagged with
+// the exact version pinned by constraint (as vX.Y.Z or X.Y.Z).
+func githubLookup(ctx context.Context, repo, constraint string) (githubRelease, error) {
+	v, pinned := semver.Exact(constraint)
+	if !pinned {
+		return githubGet(ctx, "/repos/"+repo+"/releases/latest")
+	}
+	rel, err := githubGet(ctx, "/repos/"+repo+"/releases/tags/v"+v)
+	if errors.Is(err, errNotFound) {
+		rel, err = githubGet(ctx, "/repos/"+repo+"/releases/tags/"+v)
+	}
+	return rel, err
+}
+
+var errNotFound = errors.New("not found")
+
+func githubGet(ctx context.Context, path string) (githubRelease, error) {
+	var rel githubRelease
+	base := strings.TrimSuffix(os.Getenv(EnvGitHubAPI), "/")
+	if base == "" {
+		base = "https://api.github.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+path, nil)
+	if err != nil {
+		return rel, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "dev-gadgets")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return rel, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return rel, fmt.Errorf("GET %s: %w", path, errNotFound)
+	case resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return rel, fmt.Errorf("GET %s: rate limited, set GITHUB_TOKEN", path)
+	case resp.StatusCode != http.StatusOK:
+		return rel, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return rel, json.NewDecoder(resp.Body).Decode(&rel)
+}
+
+// pickAsset keeps the assets matching the platform patterns and the item's
+// asset pattern, then prefers the right libc, archives and shorter names.
+func pickAsset(g *catalog.GitHub, assets []githubAsset, p Platform) (githubAsset, error) {
+	osPat, archPat := githubOS[p.Os], githubArch[p.Arch]
+	if v, ok := g.OS[p.Os]; ok {
+		osPat = v
+	}
+	if v, ok := g.Arch[p.Arch]; ok {
+		archPat = v
+	}
+	if osPat == "" || archPat == "" {
+		return githubAsset{}, fmt.Errorf("no asset pattern for %s/%s; set os/arch on the item", p.Os, p.Arch)
+	}
+	if p.Os == "darwin" {
+		// Universal macOS binaries run on both arches.
+		archPat = "(?:" + archPat + `)|(?i)(universal|_all\b)`
+	}
+	var patterns []*regexp.Regexp
+	for _, s := range []string{osPat, archPat, g.Asset} {
+		if s == "" {
+			continue
+		}
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return githubAsset{}, fmt.Errorf("asset pattern: %v", err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	var found []githubAsset
+	for _, a := range assets {
+		if githubSkip.MatchString(a.Name) {
+			continue
+		}
+		if !slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool { return !re.MatchString(a.Name) }) {
+			found = append(found, a)
+		}
+	}
+	if len(found) == 0 {
+		return githubAsset{}, fmt.Errorf("no asset matches %s/%s", p.Os, p.Arch)
+	}
+
+	score := func(a githubAsset) int {
+		s := 0
+		if musl := strings.Contains(strings.ToLower(a.Name), "musl"); musl == (p.Libc == "musl") {
+			s += 2
+		}
+		if archiveName.MatchString(a.Name) {
+			s++
+		}
+		return s
+	}
+	slices.SortStableFunc(found, func(a, b githubAsset) int {
+		if d := score(b) - score(a); d != 0 {
+			return d
+		}
+		return len(a.Name) - len(b.Name)
+	})
+	return found[0], nil
+}
@@ -0,0 +1,181 @@
+package install
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+// githubServer serves the given releases by API path and records the paths
+// requested.
+func githubServer(t *testing.T, releases map[string]githubRelease) *[]string {
+	t.Helper()
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		rel, ok := releases[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(rel)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv(EnvGitHubAPI, srv.URL)
+	return &paths
+}
+
+func TestGitHubLookup(t *testing.T) {
+	tests := []struct {
+		name, version, want string
+		releases            map[string]githubRelease
+		paths               []string
+	}{
+		{
+			name:     "latest",
+			want:     "v2.0.0",
+			releases: map[string]githubRelease{"/repos/o/r/releases/latest": {TagName: "v2.0.0"}},
+			paths:    []string{"/repos/o/r/releases/latest"},
+		},
+		{
+			name:     "constraint is not a pin",
+			version:  ">=1",
+			want:     "v2.0.0",
+			releases: map[string]githubRelease{"/repos/o/r/releases/latest": {TagName: "v2.0.0"}},
+			paths:    []string{"/repos/o/r/releases/latest"},
+		},
+		{
+			name:     "pinned v tag",
+			version:  "1.2.3",
+			want:     "v1.2.3",
+			releases: map[string]githubRelease{"/repos/o/r/releases/tags/v1.2.3": {TagName: "v1.2.3"}},
+			paths:    []string{"/repos/o/r/releases/tags/v1.2.3"},
+		},
+		{
+			name:     "pinned bare tag",
+			version:  "1.2.3",
+			want:     "1.2.3",
+			releases: map[string]githubRelease{"/repos/o/r/releases/tags/1.2.3": {TagName: "1.2.3"}},
+			paths:    []string{"/repos/o/r/releases/tags/v1.2.3", "/repos/o/r/releases/tags/1.2.3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := githubServer(t, tt.releases)
+			rel, err := githubLookup(context.Background(), "o/r", tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rel.TagName != tt.want {
+				t.Errorf("tag = %s, want %s", rel.TagName, tt.want)
+			}
+			if strings.Join(*paths, " ") != strings.Join(tt.paths, " ") {
+				t.Errorf("requested %v, want %v", *paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestGitHubLookupNotFound(t *testing.T) {
+	githubServer(t, nil)
+	if _, err := githubLookup(context.Background(), "o/r", "1.0.0"); err == nil {
+		t.Fatal("want an error for a missing release")
+	}
+}
+
+func TestPickAsset(t *testing.T) {
+	assets := func(names ...string) []githubAsset {
+		var as []githubAsset
+		for _, n := range names {
+			as = append(as, githubAsset{Name: n, URL: "https://example.com/" + n})
+		}
+		return as
+	}
+	goreleaser := assets(
+		"checksums.txt",
+		"tool_1.0.0_linux_amd64.deb",
+		"tool_Linux_x86_64.tar.gz",
+		"tool_Linux_x86_64.tar.gz.sbom.json",
+		"tool_Linux_arm64.tar.gz",
+		"tool_Darwin_all.tar.gz",
+		"tool_Windows_x86_64.zip",
+	)
+	rust := assets(
+		"tool-x86_64-unknown-linux-gnu.tar.gz",
+		"tool-x86_64-unknown-linux-musl.tar.gz",
+		"tool-x86_64-unknown-linux-gnu",
+		"tool-aarch64-apple-darwin.tar.gz",
+	)
+	tests := []struct {
+		name   string
+		g      catalog.GitHub
+		assets []githubAsset
+		p      Platform
+		want   string
+	}{
+		{"linux amd64", catalog.GitHub{}, goreleaser, Platform{Os: "linux", Arch: "amd64", Libc: "gnu"}, "tool_Linux_x86_64.tar.gz"},
+		{"linux arm64", catalog.GitHub{}, goreleaser, Platform{Os: "linux", Arch: "arm64", Libc: "gnu"}, "tool_Linux_arm64.tar.gz"},
+		{"darwin universal", catalog.GitHub{}, goreleaser, Platform{Os: "darwin", Arch: "arm64"}, "tool_Darwin_all.tar.gz"},
+		{"windows", catalog.GitHub{}, goreleaser, Platform{Os: "windows", Arch: "amd64"}, "tool_Windows_x86_64.zip"},
+		{"gnu prefers archive", catalog.GitHub{}, rust, Platform{Os: "linux", Arch: "amd64", Libc: "gnu"}, "tool-x86_64-unknown-linux-gnu.tar.gz"},
+		{"musl", catalog.GitHub{}, rust, Platform{Os: "linux", Arch: "amd64", Libc: "musl"}, "tool-x86_64-unknown-linux-musl.tar.gz"},
+		{"asset pattern", catalog.GitHub{Asset: `-gnu$`}, rust, Platform{Os: "linux", Arch: "amd64", Libc: "gnu"}, "tool-x86_64-unknown-linux-gnu"},
+		{"arch override", catalog.GitHub{Arch: map[string]string{"arm64": "apple"}}, rust, Platform{Os: "darwin", Arch: "arm64"}, "tool-aarch64-apple-darwin.tar.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := pickAsset(&tt.g, tt.assets, tt.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.Name != tt.want {
+				t.Errorf("picked %s, want %s", a.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickAssetErrors(t *testing.T) {
+	assets := []githubAsset{{Name: "tool_linux_amd64.tar.gz"}}
+	linux := Platform{Os: "linux", Arch: "amd64"}
+	tests := []struct {
+		name string
+		g    catalog.GitHub
+		p    Platform
+	}{
+		{"no match", catalog.GitHub{}, Platform{Os: "linux", Arch: "arm64"}},
+		{"unknown platform", catalog.GitHub{}, Platform{Os: "plan9", Arch: "amd64"}},
+		{"bad asset pattern", catalog.GitHub{Asset: "(["}, linux},
+		{"bad os pattern", catalog.GitHub{OS: map[string]string{"linux": "(["}}, linux},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := pickAsset(&tt.g, assets, tt.p); err == nil {
+				t.Error("want an error")
+			}
+		})
+	}
+}
+
+func TestRunGitHubBadPatterns(t *testing.T) {
+	t.Setenv("XDG_BIN_HOME", t.TempDir())
+	p := currentPlatform()
+	name := "tool_" + p.Os + "_" + p.Arch + ".tar.gz"
+	githubServer(t, map[string]githubRelease{
+		"/repos/o/r/releases/latest": {TagName: "v1.0.0", Assets: []githubAsset{{Name: name, URL: "http://127.0.0.1:1/" + name}}},
+	})
+	for _, g := range []catalog.GitHub{
+		{Repo: "o/r", Checksums: "(["},
+		{Repo: "o/r", Signature: &catalog.Signature{Minisign: "RW", URL: "(["}},
+	} {
+		it := catalog.Item{ID: "tool", Strategy: catalog.Strategy{GitHub: &g}}
+		if _, err := runGitHub(context.Background(), it, Options{}); err == nil || !strings.Contains(err.Error(), "missing closing") {
+			t.Errorf("runGitHub(%+v) = %v, want a regexp error", g, err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+// RunHooks runs the post_install hooks of a successful result whose
+// conditions hold. Hooks of items that were already installed only run when
+// marked always. Hooks share the terminal, so they may prompt the user.
+func RunHooks(ctx context.Context, res Result) error {
+	for _, h := range res.Item.PostInstall {
+		if res.AlreadyInstalled && !h.Always {
+			continue
+		}
+		if !hookApplies(ctx, h) {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Run)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: post_install %q failed: %v", res.Item.ID, h.Run, err)
+		}
+	}
+	return nil
+}
+
+func hookApplies(ctx context.Context, h catalog.Hook) bool {
+	kind, arg, _ := strings.Cut(h.When, ":")
+	switch {
+	case h.When == "":
+		return true
+	case h.When == "git-repo":
+		return exec.CommandContext(ctx, "git", "rev-parse", "--is-inside-work-tree").Run() == nil
+	case kind == "file":
+		_, err := os.Stat(arg)
+		return err == nil
+	case kind == "env":
+		return os.Getenv(arg) != ""
+	}
+	return false
+}
@@ -2,194 +2,339 @@ package install
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/adrg/xdg"
 	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/lock"
+	"github.com/pirpedro/dev-gadgets/internal/semver"
 )
 
 type Options struct {
 	AssumeYes bool
+	// Prefer moves these strategies to the front of the item's order.
+	Prefer []string
+	// Strategies, when set, replaces the item's order: only these are tried.
+	Strategies []string
+	// Frozen installs every item exactly as recorded in Lock, or fails.
+	Frozen bool
+	Lock   *lock.Lock
+
+	// Checksum the artifact downloaded from expectSource must match. Other
+	// platforms resolve other assets, which the lock cannot vouch for.
+	expectSHA256 string
+	expectSource string
 }
 
-func Install(ctx context.Context, it catalog.Item, opts Options) error {
-	// Idempotency: verify first
-	if it.Verify != "" {
-		if ok := verify(it.Verify); ok {
-			return nil
-		}
-	}
+// strategy is one way of installing an item, keyed by its catalog name.
+type strategy struct {
+	tool      string       // package manager that must be on PATH, if any
+	alt       string       // used instead of tool when only it is on PATH
+	bootstrap func() error // installs tool when it is missing
+	confirm   bool         // ask before using it unless AssumeYes
+	distros   []string     // os-release IDs it is limited to, if any
+	run       func(ctx context.Context, it catalog.Item, opts Options) (artifact, error)
+}
 
-	// Escolhe estratégia baseada no SO e disponibilidade
-	if it.Strategy.Release["url"] != "" {
-		return runRelease(ctx, it)
-	}
+// artifact records what a strategy installed, for the lockfile.
+type artifact struct {
+	Source string // package spec or download URL
+	SHA256 string // checksum of the downloaded file, if any
+}
 
-	// Detecta gerenciadores disponíveis
-	has := func(bin string) bool {
-		cmd := exec.Command("which", bin)
-		return cmd.Run() == nil
+// DefaultOrder is the order strategies are tried in when neither the item
+// nor the user says otherwise.
+var DefaultOrder = []string{"github", "release", "go", "cargo", "mise", "uv", "pipx", "volta", "npm", "nix", "brew", "apk", "apt", "dnf", "pacman", "zypper", "container", "script"}
+
+var strategies = map[string]strategy{
+	"release":   {run: runRelease},
+	"github":    {run: runGitHub},
+	"script":    {tool: "sh", run: runScript},
+	"container": {tool: "docker", alt: "podman", run: runContainer},
+	"go": {tool: "go", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		pkg := goPin(it)
+		return artifact{Source: pkg}, runGo(ctx, pkg)
+	}},
+	"cargo": {tool: "cargo", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		pkg := pin(it, it.Strategy.Cargo.Crate, "@")
+		return artifact{Source: pkg}, runCargo(ctx, pkg, it.Strategy.Cargo.Locked)
+	}},
+	"mise": {tool: "mise", alt: "asdf", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		version := misePin(it.Version)
+		return artifact{Source: it.Strategy.Mise + "@" + version}, runMise(ctx, it.Strategy.Mise, version)
+	}},
+	"uv": {tool: "uv", bootstrap: installUvIfNeeded, confirm: true, run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		pkg := pin(it, it.Strategy.Uv, "==")
+		return artifact{Source: pkg}, runUv(ctx, pkg)
+	}},
+	"pipx": {tool: "pipx", confirm: true, run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		pkg := pin(it, it.Strategy.Pipx, "==")
+		return artifact{Source: pkg}, runPipx(ctx, pkg)
+	}},
+	"volta": {tool: "volta", bootstrap: installVoltaIfNeeded, confirm: true, run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		pkg := pin(it, it.Strategy.Volta, "@")
+		return artifact{Source: pkg}, runVolta(ctx, pkg)
+	}},
+	"npm": {tool: "npm", confirm: true, run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		pkg := pin(it, it.Strategy.Npm, "@")
+		return artifact{Source: pkg}, runNpm(ctx, pkg)
+	}},
+	"nix": {tool: "nix", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		ref := it.Strategy.Nix.Ref()
+		return artifact{Source: ref}, runNix(ctx, ref)
+	}},
+	"brew": {tool: "brew", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		return artifact{Source: it.Strategy.Brew}, runBrew(ctx, it.Strategy.Brew)
+	}},
+	"apt": {tool: "apt-get", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		pkg := pin(it, it.Strategy.Apt, "=")
+		return artifact{Source: pkg}, runApt(ctx, pkg)
+	}},
+	"apk": {tool: "apk", distros: []string{"alpine"}, run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		pkg := pin(it, it.Strategy.Apk, "=")
+		return artifact{Source: pkg}, runApk(ctx, pkg)
+	}},
+	"dnf": {tool: "dnf", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		return artifact{Source: it.Strategy.Dnf}, runDnf(ctx, it.Strategy.Dnf)
+	}},
+	"pacman": {tool: "pacman", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		return artifact{Source: it.Strategy.Pacman}, runPacman(ctx, it.Strategy.Pacman)
+	}},
+	"zypper": {tool: "zypper", run: func(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+		return artifact{Source: it.Strategy.Zypper}, runZypper(ctx, it.Strategy.Zypper)
+	}},
+}
+
+// misePin returns the version to hand to mise or asdf: exact versions
+// without a leading v, fuzzy pins such as "20" or "lts" as written (mise
+// resolves them itself), and "latest" for ranges or no version at all.
+func misePin(version string) string {
+	if v, ok := semver.Exact(version); ok {
+		return v
+	}
+	version = strings.TrimSpace(version)
+	if version == "" || strings.ContainsAny(version, "<>=!^~, |") {
+		return "latest"
 	}
+	return version
+}
+
+// Result describes what Install did for an item.
+type Result struct {
+	Item             catalog.Item
+	AlreadyInstalled bool   // verify passed before anything was installed
+	Strategy         string // strategy that installed the item
+	Version          string // version reported by verify, if any
+	Source           string // package spec or download URL
+	SHA256           string // checksum of the downloaded artifact
+}
 
-	// Instala uv em XDG se necessário
-	installUvIfNeeded := func() error {
-		if has("uv") {
-			return nil
+func Install(ctx context.Context, it catalog.Item, opts Options) (Result, error) {
+	if opts.Frozen {
+		e, ok := opts.Lock.Get(it.ID)
+		if !ok {
+			return Result{Item: it}, fmt.Errorf("%s: not in the lockfile; run install without --frozen to lock it", it.ID)
 		}
-		// Instalação simplificada: baixa binário para XDG
-		xdgBin := getXdgBinDir()
-		url := "https://github.com/astral-sh/uv/releases/latest/download/uv-x86_64-unknown-linux-musl.tar.gz"
-		cmd := exec.Command("sh", "-c", fmt.Sprintf("curl -sSL %s | tar -xz -C %s", url, xdgBin))
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("falha ao instalar uv: %v", err)
+		if e.Version != "" {
+			it.Version = e.Version
 		}
-		return nil
+		opts.Strategies = []string{e.Strategy}
+		opts.expectSHA256, opts.expectSource = e.SHA256, e.Source
 	}
 
-	// Instala volta em XDG se necessário
-	installVoltaIfNeeded := func() error {
-		if has("volta") {
-			return nil
+	res := Result{Item: it}
+	// Idempotency: verify first
+	if it.Verify != "" {
+		if found, ok := verify(it); ok {
+			res.AlreadyInstalled, res.Version = true, found
+			return res, nil
 		}
-		xdgBin := getXdgBinDir()
-		url := "https://get.volta.sh/latest"
-		cmd := exec.Command("sh", "-c", fmt.Sprintf("curl %s | VOLTA_HOME=%s bash", url, xdgBin))
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("falha ao instalar volta: %v", err)
+	}
+	if opts.Frozen && opts.Strategies[0] == "" {
+		return res, fmt.Errorf("%s: locked without a strategy, but it is missing or at another version; run install without --frozen to relock it", it.ID)
+	}
+	name, a, err := installItem(ctx, it, opts)
+	if err != nil {
+		return res, err
+	}
+	res.Strategy, res.Source, res.SHA256 = name, a.Source, a.SHA256
+	if it.Verify != "" {
+		found, ok := verify(it)
+		switch {
+		case !ok && it.Version != "" && found != "":
+			return res, fmt.Errorf("%s: installed version %q does not satisfy %q", it.ID, found, it.Version)
+		case !ok:
+			return res, fmt.Errorf("%s: installed with %s, but %q failed", it.ID, name, it.Verify)
 		}
-		return nil
+		res.Version = found
 	}
+	return res, nil
+}
 
-	// Python: uv/pipx
-	if it.Strategy.Uv != "" {
-		if opts.AssumeYes {
-			// Modo automático: instala uv se não houver
-			if has("uv") {
-				return runUv(ctx, it.Strategy.Uv)
-			} else {
-				if err := installUvIfNeeded(); err != nil {
-					return err
-				}
-				return runUv(ctx, it.Strategy.Uv)
-			}
-		} else {
-			// Modo interativo: pergunta ao usuário
+// InstalledVersion runs the verify command of it and returns the version it
+// reports, ignoring the item's version constraint.
+func InstalledVersion(it catalog.Item) (string, bool) {
+	it.Version = ""
+	return verify(it)
+}
+
+// installItem tries the strategies of it in order until one succeeds and
+// returns its name and what it installed.
+func installItem(ctx context.Context, it catalog.Item, opts Options) (string, artifact, error) {
+	var errs []error
+	for _, name := range Order(it, opts) {
+		s, ok := usable(name, it)
+		if !ok {
+			continue
+		}
+		if s.confirm && !opts.AssumeYes {
 			var escolha string
-			fmt.Printf("Você deseja instalar com uv para %s? (s/n): ", it.ID)
+			fmt.Printf("Você deseja instalar com %s para %s? (s/n): ", name, it.ID)
 			fmt.Scanln(&escolha)
-			if escolha == "s" {
-				if !has("uv") {
-					if err := installUvIfNeeded(); err != nil {
-						return err
-					}
-				}
-				return runUv(ctx, it.Strategy.Uv)
+			if escolha != "s" {
+				continue
 			}
 		}
-	}
-	if it.Strategy.Pipx != "" {
-		if opts.AssumeYes {
-			if has("pipx") {
-				return runPipx(ctx, it.Strategy.Pipx)
-			}
-		} else {
-			var escolha string
-			fmt.Printf("Você deseja instalar com pipx para %s? (s/n): ", it.ID)
-			fmt.Scanln(&escolha)
-			if escolha == "s" && has("pipx") {
-				return runPipx(ctx, it.Strategy.Pipx)
+		if s.tool != "" && !has(s.tool) && (s.alt == "" || !has(s.alt)) {
+			if err := s.bootstrap(); err != nil {
+				errs = append(errs, err)
+				continue
 			}
 		}
+		a, err := s.run(ctx, it, opts)
+		if err == nil {
+			return name, a, nil
+		}
+		if errors.Is(err, errChecksum) || errors.Is(err, errSignature) {
+			return name, a, err
+		}
+		fmt.Fprintf(os.Stderr, "[dev-gadgets] %v; trying other strategies\n", err)
+		errs = append(errs, err)
 	}
 
-	// Node: volta/npm
-	if it.Strategy.Volta != "" {
-		if opts.AssumeYes {
-			if has("volta") {
-				return runVolta(ctx, it.Strategy.Volta)
-			} else {
-				if err := installVoltaIfNeeded(); err != nil {
-					return err
-				}
-				return runVolta(ctx, it.Strategy.Volta)
-			}
-		} else {
-			var escolha string
-			fmt.Printf("Você deseja instalar com volta para %s? (s/n): ", it.ID)
-			fmt.Scanln(&escolha)
-			if escolha == "s" {
-				if !has("volta") {
-					if err := installVoltaIfNeeded(); err != nil {
-						return err
-					}
-				}
-				return runVolta(ctx, it.Strategy.Volta)
-			}
-		}
+	if len(errs) > 0 {
+		return "", artifact{}, fmt.Errorf("no viable strategy for %s: %v", it.ID, errors.Join(errs...))
 	}
-	if it.Strategy.Npm != "" {
-		if opts.AssumeYes {
-			if has("npm") {
-				return runNpm(ctx, it.Strategy.Npm)
-			}
-		} else {
-			var escolha string
-			fmt.Printf("Você deseja instalar com npm para %s? (s/n): ", it.ID)
-			fmt.Scanln(&escolha)
-			if escolha == "s" && has("npm") {
-				return runNpm(ctx, it.Strategy.Npm)
-			}
+	return "", artifact{}, fmt.Errorf("no viable strategy for %s", it.ID)
+}
+
+// usable reports whether strategy name can be tried for it on this machine:
+// the item declares it, it is meant for this distro and its tool is on PATH
+// or can be bootstrapped.
+func usable(name string, it catalog.Item) (strategy, bool) {
+	s, ok := strategies[name]
+	if !ok || !it.Strategy.Declared(name) {
+		return s, false
+	}
+	if len(s.distros) > 0 && !onDistro(s.distros) {
+		return s, false
+	}
+	if s.tool != "" && !has(s.tool) && (s.alt == "" || !has(s.alt)) && s.bootstrap == nil {
+		return s, false
+	}
+	return s, true
+}
+
+// Resolve returns the strategy an install of it would try first on this
+// machine, without running it. The lockfile uses it for items that were
+// already present, so they can be reproduced elsewhere.
+func Resolve(it catalog.Item, opts Options) (string, bool) {
+	for _, name := range Order(it, opts) {
+		if _, ok := usable(name, it); ok {
+			return name, true
 		}
 	}
+	return "", false
+}
 
-	// Demais gerenciadores
-	if it.Strategy.Brew != "" && has("brew") {
-		return runBrew(ctx, it.Strategy.Brew)
+// Order returns the strategy names to try for it: the --strategy list when
+// given, otherwise the item's own order (or DefaultOrder) with the preferred
+// strategies moved to the front.
+func Order(it catalog.Item, opts Options) []string {
+	if len(opts.Strategies) > 0 {
+		return opts.Strategies
 	}
-	if it.Strategy.Apt != "" && has("apt-get") {
-		return runApt(ctx, it.Strategy.Apt)
+	order := it.Strategy.Order
+	if len(order) == 0 {
+		order = DefaultOrder
 	}
-	if it.Strategy.Dnf != "" && has("dnf") {
-		return runDnf(ctx, it.Strategy.Dnf)
+	order = slices.Clone(order)
+	rank := func(name string) int {
+		if i := slices.Index(opts.Prefer, name); i >= 0 {
+			return i
+		}
+		return len(opts.Prefer)
 	}
-	if it.Strategy.Pacman != "" && has("pacman") {
-		return runPacman(ctx, it.Strategy.Pacman)
+	slices.SortStableFunc(order, func(a, b string) int { return rank(a) - rank(b) })
+	return order
+}
+
+// Detecta gerenciadores disponíveis
+func has(bin string) bool {
+	cmd := exec.Command("which", bin)
+	return cmd.Run() == nil
+}
+
+// uvRelease é o binário estático (musl) do uv para a plataforma atual
+var uvRelease = catalog.Release{
+	URL:       "https://github.com/astral-sh/uv/releases/latest/download/uv-{{.Arch}}-{{.Os}}.tar.gz",
+	Checksums: "https://github.com/astral-sh/uv/releases/latest/download/uv-{{.Arch}}-{{.Os}}.tar.gz.sha256",
+	Bin:       "uv",
+	OS:        map[string]string{"linux": "unknown-linux-musl", "darwin": "apple-darwin"},
+	Arch:      map[string]string{"amd64": "x86_64", "arm64": "aarch64"},
+}
+
+// Instala uv em XDG se necessário
+func installUvIfNeeded() error {
+	if has("uv") {
+		return nil
 	}
-	if it.Strategy.Zypper != "" && has("zypper") {
-		return runZypper(ctx, it.Strategy.Zypper)
+	uv := catalog.Item{ID: "uv", Strategy: catalog.Strategy{Release: &uvRelease}}
+	if _, err := runRelease(context.Background(), uv, Options{}); err != nil {
+		return fmt.Errorf("falha ao instalar uv: %v", err)
 	}
+	return nil
+}
 
-	return fmt.Errorf("no viable strategy for %s", it.ID)
+// Instala volta em XDG se necessário
+func installVoltaIfNeeded() error {
+	if has("volta") {
+		return nil
+	}
+	xdgBin := getXdgBinDir()
+	url := "https://get.volta.sh/latest"
+	cmd := exec.Command("sh", "-c", fmt.Sprintf("curl %s | VOLTA_HOME=%s bash", url, xdgBin))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("falha ao instalar volta: %v", err)
+	}
+	return nil
 }
 
 // Retorna o diretório XDG para binários
 func getXdgBinDir() string {
-	// Usa github.com/adrg/xdg
-	// Se não conseguir, fallback para ~/.local/bin
-	dir := ""
-	if xdgBin, err := exec.Command("sh", "-c", "echo $XDG_DATA_HOME").Output(); err == nil && len(xdgBin) > 0 {
-		dir = strings.TrimSpace(string(xdgBin)) + "/bin"
-	} else {
-		dir = fmt.Sprintf("%s/.local/bin", strings.TrimSpace(os.Getenv("HOME")))
-	}
+	dir := BinDir()
 	// Verifica se está no PATH
 	path := os.Getenv("PATH")
 	if !strings.Contains(path, dir) {
-		fmt.Fprintf(os.Stderr, "\n[dev-gadgets] Dica: adicione %s ao seu PATH para usar os binários instalados!\n", dir)
+		fmt.Fprintf(os.Stderr, "\n[dev-gadgets] Dica: adicione %s ao seu PATH para usar os binários instalados (veja dev-gadgets shell-init)!\n", dir)
 	}
 	return dir
 }
 
-func verify(cmdline string) bool {
-	parts := strings.Fields(cmdline)
-	if len(parts) == 0 {
-		return false
+// BinDir usa XDG_BIN_HOME quando definido, senão $XDG_DATA_HOME/bin (onde
+// as versões anteriores instalavam), senão ~/.local/bin
+func BinDir() string {
+	if dir := strings.TrimSpace(os.Getenv("XDG_BIN_HOME")); dir != "" {
+		return dir
 	}
-	cmd := exec.Command(parts[0], parts[1:]...)
-	if err := cmd.Run(); err == nil {
-		return true
+	if dir := strings.TrimSpace(os.Getenv("XDG_DATA_HOME")); dir != "" {
+		return filepath.Join(dir, "bin")
 	}
-	return false
+	return filepath.Join(xdg.Home, ".local", "bin")
 }
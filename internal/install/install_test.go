@@ -0,0 +1,71 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+func TestMisePin(t *testing.T) {
+	tests := []struct{ version, want string }{
+		{"", "latest"},
+		{"20.11.1", "20.11.1"},
+		{"v20.11.1", "20.11.1"},
+		{"20", "20"},
+		{"20.11", "20.11"},
+		{"lts", "lts"},
+		{"latest", "latest"},
+		{">=20", "latest"},
+		{"^20.1", "latest"},
+		{">=1.2, <2", "latest"},
+	}
+	for _, tt := range tests {
+		if got := misePin(tt.version); got != tt.want {
+			t.Errorf("misePin(%q) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyFuzzyPins(t *testing.T) {
+	tests := []struct {
+		version, mise string
+		want          bool
+	}{
+		{"20", "node", true},
+		{"18", "node", false},
+		{"lts", "node", true},
+		{"lts", "", false},
+	}
+	for _, tt := range tests {
+		it := catalog.Item{ID: "node", Verify: "echo v20.11.1", Version: tt.version, Strategy: catalog.Strategy{Mise: tt.mise}}
+		if _, ok := verify(it); ok != tt.want {
+			t.Errorf("verify with version %q (mise %q) = %v, want %v", tt.version, tt.mise, ok, tt.want)
+		}
+	}
+}
+
+func TestRunMisePassesPin(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args.log")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %q\n", log)
+	if err := os.WriteFile(filepath.Join(dir, "mise"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	for _, version := range []string{"20", "lts", "20.11.1", ">=20"} {
+		it := catalog.Item{ID: "node", Version: version, Strategy: catalog.Strategy{Mise: "node"}}
+		if _, err := strategies["mise"].run(context.Background(), it, Options{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, _ := os.ReadFile(log)
+	want := "use --global --yes node@20\nuse --global --yes node@lts\nuse --global --yes node@20.11.1\nuse --global --yes node@latest\n"
+	if string(b) != want {
+		t.Errorf("mise calls:\n%s\nwant:\n%s", b, want)
+	}
+}
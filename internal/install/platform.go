@@ -0,0 +1,101 @@
+package install
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/semver"
+)
+
+// Platform is the data available to release URL and bin templates.
+type Platform struct {
+	Os      string
+	Arch    string
+	Libc    string // gnu or musl on Linux, empty elsewhere
+	Version string // exact pinned version, without a leading "v"
+}
+
+func currentPlatform() Platform {
+	return Platform{Os: runtime.GOOS, Arch: runtime.GOARCH, Libc: detectLibc()}
+}
+
+// detectLibc tells musl distros (Alpine, Void musl) apart from glibc ones.
+func detectLibc() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	for _, pattern := range []string{"/lib/ld-musl-*", "/lib/libc.musl-*"} {
+		if m, _ := filepath.Glob(pattern); len(m) > 0 {
+			return "musl"
+		}
+	}
+	return "gnu"
+}
+
+// osRelease is where Linux distros describe themselves.
+const osRelease = "/etc/os-release"
+
+// detectDistro returns the ID and ID_LIKE entries of os-release, e.g.
+// [alpine] or [ubuntu debian].
+func detectDistro() []string {
+	b, err := os.ReadFile(osRelease)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, line := range strings.Split(string(b), "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && (k == "ID" || k == "ID_LIKE") {
+			ids = append(ids, strings.Fields(strings.Trim(v, `"'`))...)
+		}
+	}
+	return ids
+}
+
+// onDistro reports whether the current distro is, or is like, one of ids.
+func onDistro(ids []string) bool {
+	return slices.ContainsFunc(detectDistro(), func(id string) bool { return slices.Contains(ids, id) })
+}
+
+// forRelease translates the platform into the spelling used by r.
+func (p Platform) forRelease(r *catalog.Release) Platform {
+	lookup := func(m map[string]string, k string) string {
+		if v, ok := m[k]; ok {
+			return v
+		}
+		return k
+	}
+	p.Os = lookup(r.OS, p.Os)
+	p.Arch = lookup(r.Arch, p.Arch)
+	p.Libc = lookup(r.Libc, p.Libc)
+	return p
+}
+
+// expand renders a release URL or bin template for the current platform.
+func expand(it catalog.Item, tmpl string) (string, error) {
+	p := currentPlatform()
+	if r := it.Strategy.Release; r != nil {
+		p = p.forRelease(r)
+	}
+	if v, ok := semver.Exact(it.Version); ok {
+		p.Version = v
+	} else if strings.Contains(tmpl, ".Version") {
+		return "", fmt.Errorf("%s: release template uses {{.Version}} but no exact version is pinned", it.ID)
+	}
+	t, err := template.New(it.ID).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, p); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
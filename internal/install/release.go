@@ -1,13 +1,239 @@
 package install
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
+	"github.com/adrg/xdg"
+	"github.com/pirpedro/dev-gadgets/internal/cache"
 	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/semver"
+	"github.com/pirpedro/dev-gadgets/internal/shell"
 )
 
-func runRelease(ctx context.Context, it catalog.Item) error {
-	// TODO: download it.Strategy.Release["url"], extract, place bin in ~/.local/bin (use map["bin"] for name)
-	return fmt.Errorf("release strategy not implemented: %s", it.ID)
+// runRelease downloads the release artifact of it, extracts the binary named
+// by Release.Bin (defaults to the item ID) and places it in the user bin dir.
+func runRelease(ctx context.Context, it catalog.Item, opts Options) (artifact, error) {
+	r := it.Strategy.Release
+	url, err := releaseURL(it, r.URL)
+	if err != nil {
+		return artifact{}, err
+	}
+	files, err := payloadFiles(it, r.Bin, r.Payload)
+	if err != nil {
+		return artifact{Source: url}, err
+	}
+	sums := ""
+	if r.Checksums != "" {
+		if sums, err = releaseURL(it, r.Checksums); err != nil {
+			return artifact{Source: url}, err
+		}
+	}
+	sigURL := ""
+	if r.Signature != nil {
+		// The signature covers the checksums file when there is one.
+		sigURL = cmp.Or(sums, url) + signatureExt(r.Signature)
+		if r.Signature.URL != "" {
+			if sigURL, err = releaseURL(it, r.Signature.URL); err != nil {
+				return artifact{Source: url}, err
+			}
+		}
+	}
+	t, err := resolveTrust(ctx, path.Base(url), r.SHA256, sums, r.Signature, sigURL)
+	if err != nil {
+		return artifact{Source: url}, fmt.Errorf("%s: %w", it.ID, err)
+	}
+	return fetchPayload(ctx, it, url, files, t, opts)
+}
+
+// payloadFiles renders the file list of a release: its binaries (bins, else
+// bin, else the item ID) into the bin dir, completions and man pages into
+// their per-user directories.
+func payloadFiles(it catalog.Item, bin string, p catalog.Payload) ([]payloadFile, error) {
+	bins := p.Bins
+	if len(bins) == 0 {
+		bins = []string{cmp.Or(bin, it.ID)}
+	}
+	var files []payloadFile
+	add := func(member string, dest func(base string) string, mode fs.FileMode) error {
+		m, err := expand(it, member)
+		if err != nil {
+			return err
+		}
+		files = append(files, payloadFile{member: m, dest: dest(path.Base(m)), mode: mode})
+		return nil
+	}
+
+	binDir := getXdgBinDir()
+	for _, b := range bins {
+		if err := add(b, func(base string) string { return filepath.Join(binDir, base) }, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	command := path.Base(files[0].member)
+	for _, sh := range catalog.Shells {
+		if member, ok := p.Completions[sh]; ok {
+			if err := add(member, func(string) string { return shell.CompletionPath(sh, command) }, 0o644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, m := range p.Man {
+		if err := add(m, manPath, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// manPath places a man page (goreleaser.1 or goreleaser.1.gz) in the section
+// dir of the per-user man tree, which man finds next to ~/.local/bin.
+func manPath(base string) string {
+	name := strings.TrimSuffix(base, ".gz")
+	section := "1"
+	if ext := path.Ext(name); len(ext) > 1 && ext[1] >= '1' && ext[1] <= '9' {
+		section = ext[1:2]
+	}
+	return filepath.Join(xdg.DataHome, "man", "man"+section, base)
+}
+
+// trust is what a download is checked against before it is installed.
+type trust struct {
+	sha256 string
+	sig    *catalog.Signature // over the artifact itself, when set
+	sigURL string
+}
+
+// resolveTrust fetches the checksums file at sumsURL, if any, checks its
+// signature and looks up the checksum of name in it unless sha is given.
+// Without a checksums file the signature is left to check on the artifact.
+func resolveTrust(ctx context.Context, name, sha, sumsURL string, sig *catalog.Signature, sigURL string) (trust, error) {
+	t := trust{sha256: sha}
+	if sumsURL == "" {
+		if sig != nil {
+			t.sig, t.sigURL = sig, sigURL
+		}
+		return t, nil
+	}
+	sums, err := fetch(ctx, sumsURL)
+	if err != nil {
+		return t, fmt.Errorf("checksums: %v", err)
+	}
+	if sig != nil {
+		s, err := fetch(ctx, sigURL)
+		if err != nil {
+			return t, fmt.Errorf("signature: %v", err)
+		}
+		if err := verifySignature(sig, sums, s); err != nil {
+			return t, err
+		}
+	}
+	if t.sha256 == "" {
+		if t.sha256, err = parseChecksums(string(sums), name); err != nil {
+			return t, fmt.Errorf("checksums: %v", err)
+		}
+	}
+	return t, nil
+}
+
+// errChecksum stops the install instead of falling through to the next
+// strategy: a tampered download should not go unnoticed.
+var errChecksum = errors.New("checksum mismatch")
+
+// fetchPayload downloads url, checks it against t and, when url is the one
+// that was locked, the locked checksum, and installs the files it contains. Nothing is installed when a checksum
+// or signature does not match.
+func fetchPayload(ctx context.Context, it catalog.Item, url string, files []payloadFile, t trust, opts Options) (artifact, error) {
+	a := artifact{Source: url}
+	locked := ""
+	if url == opts.expectSource {
+		locked = opts.expectSHA256
+	}
+	file, sum, err := cache.Default().Fetch(ctx, url, cmp.Or(t.sha256, locked))
+	if err != nil {
+		return a, fmt.Errorf("release download failed for %s: %v", it.ID, err)
+	}
+	a.SHA256 = sum
+	for _, w := range []string{t.sha256, locked} {
+		if w != "" && !strings.EqualFold(a.SHA256, w) {
+			return a, fmt.Errorf("%w for %s: got %s, want %s", errChecksum, it.ID, a.SHA256, w)
+		}
+	}
+	if t.sig != nil {
+		sig, err := fetch(ctx, t.sigURL)
+		if err != nil {
+			return a, fmt.Errorf("%s: signature: %v", it.ID, err)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return a, err
+		}
+		if err := verifySignature(t.sig, data, sig); err != nil {
+			return a, fmt.Errorf("%s: %w", it.ID, err)
+		}
+	}
+
+	u, _, _ := strings.Cut(url, "?")
+	name := path.Base(u)
+	if err := extractFiles(file, name, files); err != nil {
+		return a, fmt.Errorf("release extract failed for %s: %v", it.ID, err)
+	}
+	return a, nil
+}
+
+// fetch GETs a small file such as a checksums list or a signature.
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "dev-gadgets")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// parseChecksums reads a sha256sum-style file ("<hex>  <name>" lines, as
+// GoReleaser writes them) and returns the checksum listed for name. A file
+// holding a single bare checksum applies to any name.
+func parseChecksums(text, name string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for _, line := range lines {
+		f := strings.Fields(line)
+		switch {
+		case len(f) == 1 && len(lines) == 1:
+			return f[0], nil
+		case len(f) == 2 && path.Base(strings.TrimPrefix(f[1], "*")) == name:
+			return f[0], nil
+		}
+	}
+	return "", fmt.Errorf("no checksum listed for %s", name)
+}
+
+// releaseURL renders a URL template of it and points "latest" download
+// URLs at the tag of the pinned version.
+func releaseURL(it catalog.Item, tmpl string) (string, error) {
+	url, err := expand(it, tmpl)
+	if err != nil {
+		return "", err
+	}
+	if v, ok := semver.Exact(it.Version); ok {
+		url = strings.Replace(url, "/releases/latest/download/", "/releases/download/v"+v+"/", 1)
+	}
+	return url, nil
 }
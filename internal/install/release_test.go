@@ -0,0 +1,131 @@
+package install
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+func TestParseChecksums(t *testing.T) {
+	const sums = "aaa  tool_linux_amd64.tar.gz\nbbb *tool_darwin_arm64.tar.gz\nccc  dist/tool.zip\n"
+	tests := []struct {
+		text, name, want string
+		ok               bool
+	}{
+		{sums, "tool_linux_amd64.tar.gz", "aaa", true},
+		{sums, "tool_darwin_arm64.tar.gz", "bbb", true},
+		{sums, "tool.zip", "ccc", true},
+		{sums, "tool_windows_amd64.zip", "", false},
+		{"ddd\n", "anything", "ddd", true},
+		{"", "tool", "", false},
+	}
+	for _, tt := range tests {
+		got, err := parseChecksums(tt.text, tt.name)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("parseChecksums(%q, %s) = %q, %v; want %q", tt.text, tt.name, got, err, tt.want)
+		}
+	}
+}
+
+// releaseEnv points the bin dir and download cache at temp dirs.
+func releaseEnv(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	t.Setenv("XDG_BIN_HOME", binDir)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	xdg.Reload()
+	t.Cleanup(xdg.Reload)
+	return binDir
+}
+
+func TestRunReleaseChecksums(t *testing.T) {
+	tool := []byte("#!/bin/sh\necho tool 1.0.0\n")
+	h := sha256.Sum256(tool)
+	good := hex.EncodeToString(h[:])
+	bad := hex.EncodeToString(make([]byte, 32))
+
+	tests := []struct {
+		name, sums, sha256 string
+		wantErr            error
+	}{
+		{"checksums file", good + "  tool\n", "", nil},
+		{"sha256", "", good, nil},
+		{"bad checksums file", bad + "  tool\n", "", errChecksum},
+		{"bad sha256", "", bad, errChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binDir := releaseEnv(t)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/tool":
+					w.Write(tool)
+				case "/checksums.txt":
+					w.Write([]byte(tt.sums))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			rel := &catalog.Release{URL: srv.URL + "/tool", SHA256: tt.sha256}
+			if tt.sums != "" {
+				rel.Checksums = srv.URL + "/checksums.txt"
+			}
+			it := catalog.Item{ID: "tool", Strategy: catalog.Strategy{Release: rel}}
+			a, err := runRelease(context.Background(), it, Options{})
+			entries, _ := os.ReadDir(binDir)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if len(entries) != 0 {
+					t.Errorf("bin dir has %d entries after a mismatch, want none", len(entries))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a.SHA256 != good {
+				t.Errorf("sha256 = %s, want %s", a.SHA256, good)
+			}
+			if b, err := os.ReadFile(filepath.Join(binDir, "tool")); err != nil || string(b) != string(tool) {
+				t.Errorf("installed tool = %q, %v", b, err)
+			}
+		})
+	}
+}
+
+func TestRunReleaseLockedChecksum(t *testing.T) {
+	binDir := releaseEnv(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("changed upstream"))
+	}))
+	defer srv.Close()
+
+	it := catalog.Item{ID: "tool", Strategy: catalog.Strategy{Release: &catalog.Release{URL: srv.URL + "/tool"}}}
+	locked := hex.EncodeToString(make([]byte, 32))
+	_, err := runRelease(context.Background(), it, Options{expectSHA256: locked, expectSource: srv.URL + "/tool"})
+	if !errors.Is(err, errChecksum) {
+		t.Fatalf("err = %v, want errChecksum", err)
+	}
+	if entries, _ := os.ReadDir(binDir); len(entries) != 0 {
+		t.Errorf("bin dir has %d entries, want none", len(entries))
+	}
+
+	// Locked on another platform: its asset is not the one resolved here.
+	opts := Options{expectSHA256: locked, expectSource: srv.URL + "/tool-darwin-arm64"}
+	if _, err := runRelease(context.Background(), it, opts); err != nil {
+		t.Fatalf("checksum of another platform's asset was enforced: %v", err)
+	}
+}
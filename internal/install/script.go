@@ -0,0 +1,86 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/semver"
+)
+
+// runScript runs the script steps of it in a temp dir, checks that every
+// declared output was produced and copies the outputs into the user bin dir.
+func runScript(ctx context.Context, it catalog.Item, _ Options) (artifact, error) {
+	sc := it.Strategy.Script
+	a := artifact{Source: "script"}
+
+	work, err := os.MkdirTemp("", "dev-gadgets-script-*")
+	if err != nil {
+		return a, err
+	}
+	defer os.RemoveAll(work)
+
+	env := scriptEnv(it, work)
+	for i, step := range sc.Steps {
+		cmd := exec.CommandContext(ctx, "sh", "-ec", step)
+		cmd.Dir = work
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return a, fmt.Errorf("script step %d of %s failed: %v\n%s", i+1, it.ID, err, out)
+		}
+	}
+
+	for _, out := range sc.Outputs {
+		fi, err := os.Stat(filepath.Join(work, out))
+		if err != nil || !fi.Mode().IsRegular() {
+			return a, fmt.Errorf("script for %s did not produce %s", it.ID, out)
+		}
+	}
+	binDir := getXdgBinDir()
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return a, err
+	}
+	for _, out := range sc.Outputs {
+		f, err := os.Open(filepath.Join(work, out))
+		if err != nil {
+			return a, err
+		}
+		err = writeExecutable(filepath.Join(binDir, filepath.Base(out)), f)
+		f.Close()
+		if err != nil {
+			return a, err
+		}
+	}
+	return a, nil
+}
+
+// scriptEnv is the environment of the script steps: the basics a shell needs,
+// the platform as DEV_GADGETS_* variables and the item's own env.
+func scriptEnv(it catalog.Item, work string) []string {
+	p := currentPlatform()
+	version, _ := semver.Exact(it.Version)
+	env := []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + os.Getenv("HOME"),
+		"TMPDIR=" + work,
+		"LANG=C",
+		"DEV_GADGETS_OS=" + p.Os,
+		"DEV_GADGETS_ARCH=" + p.Arch,
+		"DEV_GADGETS_LIBC=" + p.Libc,
+		"DEV_GADGETS_VERSION=" + version,
+		"DEV_GADGETS_BIN_DIR=" + BinDir(),
+	}
+	keys := make([]string, 0, len(it.Strategy.Script.Env))
+	for k := range it.Strategy.Script.Env {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+it.Strategy.Script.Env[k])
+	}
+	return env
+}
@@ -0,0 +1,79 @@
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+func TestRunScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []string
+		outputs []string
+		env     map[string]string
+		want    map[string]string // bin dir contents
+		wantErr string
+	}{
+		{
+			name: "restricted env in a temp dir",
+			steps: []string{
+				`[ "$(pwd -P)" = "$(cd "$TMPDIR" && pwd -P)" ]`,
+				`printf '%s:%s:%s\n' "$FOO" "${SECRET_TOKEN-unset}" "$DEV_GADGETS_VERSION" > env`,
+			},
+			outputs: []string{"env"},
+			env:     map[string]string{"FOO": "bar"},
+			want:    map[string]string{"env": "bar:unset:1.2.3\n"},
+		},
+		{
+			name:    "missing output",
+			steps:   []string{"printf tool > tool"},
+			outputs: []string{"tool", "tool.1"},
+			want:    map[string]string{},
+			wantErr: "did not produce tool.1",
+		},
+		{
+			name:    "outputs land in the bin dir",
+			steps:   []string{"printf tool > tool", "mkdir -p dist && printf lib > dist/tool-lib"},
+			outputs: []string{"tool", "dist/tool-lib"},
+			want:    map[string]string{"tool": "tool", "tool-lib": "lib"},
+		},
+	}
+	t.Setenv("SECRET_TOKEN", "leaked")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binDir := t.TempDir()
+			t.Setenv("XDG_BIN_HOME", binDir)
+			it := catalog.Item{
+				ID:       "tool",
+				Version:  "1.2.3",
+				Strategy: catalog.Strategy{Script: &catalog.Script{Steps: tt.steps, Outputs: tt.outputs, Env: tt.env}},
+			}
+			_, err := runScript(context.Background(), it, Options{})
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+
+			entries, err := os.ReadDir(binDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Errorf("bin dir has %d files, want %d", len(entries), len(tt.want))
+			}
+			for name, want := range tt.want {
+				got, err := os.ReadFile(filepath.Join(binDir, name))
+				if err != nil || string(got) != want {
+					t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package install
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"golang.org/x/crypto/blake2b"
+)
+
+// errSignature, like errChecksum, stops the install instead of falling
+// through to the next strategy.
+var errSignature = errors.New("signature verification failed")
+
+// signatureExt is appended to the signed file's URL or name when the
+// signature location is not given.
+func signatureExt(s *catalog.Signature) string {
+	if s.Minisign != "" {
+		return ".minisig"
+	}
+	return ".sig"
+}
+
+// verifySignature checks the detached signature sig over msg with the key
+// configured in s. Everything happens offline.
+func verifySignature(s *catalog.Signature, msg, sig []byte) error {
+	var err error
+	if s.Minisign != "" {
+		err = verifyMinisign(s.Minisign, msg, sig)
+	} else {
+		err = verifyCosign(s.Cosign, msg, sig)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errSignature, err)
+	}
+	return nil
+}
+
+// verifyMinisign checks a minisign signature file: an untrusted comment, the
+// signature (legacy "Ed" over the message or "ED" over its BLAKE2b-512), a
+// trusted comment and the global signature over signature and comment.
+func verifyMinisign(pubKey string, msg, sigFile []byte) error {
+	pk, err := minisignBlob(lastLine(pubKey), 42)
+	if err != nil {
+		return fmt.Errorf("minisign public key: %v", err)
+	}
+	if string(pk[:2]) != "Ed" {
+		return errors.New("minisign public key: unsupported algorithm")
+	}
+	keyID, pub := pk[2:10], ed25519.PublicKey(pk[10:])
+
+	lines := strings.Split(strings.TrimSpace(string(sigFile)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return errors.New("malformed minisign signature")
+	}
+	sig, err := minisignBlob(lines[1], 74)
+	if err != nil {
+		return fmt.Errorf("minisign signature: %v", err)
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return errors.New("minisign signature: malformed global signature")
+	}
+	if !bytes.Equal(sig[2:10], keyID) {
+		return errors.New("minisign signature made with another key")
+	}
+
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		h := blake2b.Sum512(msg)
+		msg = h[:]
+	default:
+		return errors.New("minisign signature: unsupported algorithm")
+	}
+	if !ed25519.Verify(pub, msg, sig[10:]) {
+		return errors.New("minisign signature does not match")
+	}
+	comment := strings.TrimPrefix(strings.TrimRight(lines[2], "\r"), "trusted comment: ")
+	if !ed25519.Verify(pub, append(sig[10:74:74], comment...), global) {
+		return errors.New("minisign trusted comment does not match")
+	}
+	return nil
+}
+
+func minisignBlob(s string, size int) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", size, len(b))
+	}
+	return b, nil
+}
+
+// lastLine drops the untrusted comment of a minisign key file.
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	return s[strings.LastIndex(s, "\n")+1:]
+}
+
+// verifyCosign checks a base64 signature made by cosign sign-blob --key:
+// ECDSA over the SHA-256 of msg, or Ed25519 over msg itself.
+func verifyCosign(pemKey string, msg, sigFile []byte) error {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return errors.New("cosign public key: no PEM block")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("cosign public key: %v", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigFile)))
+	if err != nil {
+		return fmt.Errorf("cosign signature: %v", err)
+	}
+	ok := false
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		h := sha256.Sum256(msg)
+		ok = ecdsa.VerifyASN1(k, h[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, msg, sig)
+	default:
+		return fmt.Errorf("cosign public key: unsupported type %T", key)
+	}
+	if !ok {
+		return errors.New("cosign signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package install
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+// Made with aead.dev/minisign over minisignMsg: an "ED" (prehashed)
+// signature and a legacy "Ed" one.
+const (
+	minisignMsg = "2d711642b726b04401627ca9fbac32f5c8530fb1903cc4db02258717921a4881  tool\n"
+	minisignKey = `untrusted comment: minisign public key: 6B7FE91D572BF93E
+RWQ++StXHel/axqosLZHUw+UyKA0c1mju73N+JkmWgIWtDfEpCqtJYyz`
+	minisignED = `untrusted comment: signature from private key: 6B7FE91D572BF93E
+RUQ++StXHel/a1dGtWEBMB99/v4Bl5lTs2FCctW2XoHA2uhIyaIhJqn0lLmnnBs2+H+vJfFz8SVQ4T65q9TFii6Uxx194nvOIw4=
+trusted comment: timestamp:1792309754
+yV08w/sQkgJgfvSaaFxLiqGEgdERfYEj/YfyGjx5qQezVoAGB0Gn31VFSRGP3HOb1Vww5CzUIGGjIc9T3N5xAw==
+`
+	minisignEd = `untrusted comment: signature from private key: 6B7FE91D572BF93E
+RWQ++StXHel/a1mH//3CQwgf+rncSodVUqY69HxbkvCRYBkU3Rhe6jKCSXL5aw0DGO7f+lrNKD+Iv5vpZsEodQQY76B6NuTBBQ0=
+trusted comment: timestamp:1792309754
+bTvMnkXh5NQaNtWcpe1xSJc279VjTEaq7gzsxJpnr70vvqk507guzve81JjwTBdRWcgeoj09QoIRGloMZNDPCw==
+`
+	// Made with openssl over minisignMsg, as cosign sign-blob --key does.
+	cosignECKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEkOrjf8RVZJH80ytMK4vVok6qTIOK
+VJwJeB4/Nk4ur8Ocw7e3aQ01YpiRT+pPnZ/WYULdtnNLdbnfkVYCOOCaQQ==
+-----END PUBLIC KEY-----
+`
+	cosignECSig = "MEUCIBhY8E0gMA0l7mc5eyh5kONiO8qb9xokFDwrut9vs1SKAiEAmYRbKLHtWE7y5vUP2bVsFRQ/EHrvvZsypMi20cXcnzU=\n"
+)
+
+func TestVerifyMinisign(t *testing.T) {
+	// Another key: same algorithm, different key ID.
+	otherKey := "RWQAAAAAAAAAAAqosLZHUw+UyKA0c1mju73N+JkmWgIWtDfEpCqtJYyz"
+	tests := []struct {
+		name     string
+		key, msg string
+		sig      string
+		wantErr  string
+	}{
+		{"prehashed", minisignKey, minisignMsg, minisignED, ""},
+		{"legacy", minisignKey, minisignMsg, minisignEd, ""},
+		{"bare key line", lastLine(minisignKey), minisignMsg, minisignED, ""},
+		{"crlf", minisignKey, minisignMsg, strings.ReplaceAll(minisignED, "\n", "\r\n"), ""},
+		{"tampered message", minisignKey, strings.Replace(minisignMsg, "2d", "3d", 1), minisignED, "does not match"},
+		{"tampered legacy message", minisignKey, minisignMsg + " ", minisignEd, "does not match"},
+		{"tampered trusted comment", minisignKey, minisignMsg, strings.Replace(minisignED, "1792309754", "1792309755", 1), "trusted comment does not match"},
+		{"key ID mismatch", otherKey, minisignMsg, minisignED, "another key"},
+		{"malformed", minisignKey, minisignMsg, "untrusted comment: x\n", "malformed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyMinisign(tt.key, []byte(tt.msg), []byte(tt.sig))
+			checkErr(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifyCosign(t *testing.T) {
+	msg := []byte(minisignMsg)
+
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256(msg)
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecPriv, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSig := ed25519.Sign(edPriv, msg)
+
+	pemKey := func(pub any) string {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+	b64 := base64.StdEncoding.EncodeToString
+
+	tests := []struct {
+		name    string
+		key     string
+		msg     []byte
+		sig     string
+		wantErr string
+	}{
+		{"ecdsa from openssl", cosignECKey, msg, cosignECSig, ""},
+		{"ecdsa", pemKey(&ecPriv.PublicKey), msg, b64(ecSig), ""},
+		{"ed25519", pemKey(edPub), msg, b64(edSig), ""},
+		{"ecdsa tampered", cosignECKey, append(msg, 'x'), cosignECSig, "does not match"},
+		{"ecdsa other key", pemKey(&ecPriv.PublicKey), msg, cosignECSig, "does not match"},
+		{"ed25519 tampered", pemKey(edPub), append(msg, 'x'), b64(edSig), "does not match"},
+		{"not base64", cosignECKey, msg, "!!", "cosign signature"},
+		{"no pem", "RWQ...", msg, cosignECSig, "no PEM block"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErr(t, verifyCosign(tt.key, tt.msg, []byte(tt.sig)), tt.wantErr)
+		})
+	}
+}
+
+func TestVerifySignatureWrapsErr(t *testing.T) {
+	s := &catalog.Signature{Minisign: minisignKey}
+	if err := verifySignature(s, []byte(minisignMsg), []byte(minisignED)); err != nil {
+		t.Fatal(err)
+	}
+	err := verifySignature(s, []byte("other"), []byte(minisignED))
+	if !errors.Is(err, errSignature) {
+		t.Errorf("err = %v, want errSignature", err)
+	}
+}
+
+func checkErr(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case want != "" && err == nil:
+		t.Errorf("want an error containing %q", want)
+	case want != "" && !strings.Contains(err.Error(), want):
+		t.Errorf("err = %v, want it to contain %q", err, want)
+	}
+}
@@ -3,7 +3,12 @@ package install
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pirpedro/dev-gadgets/internal/semver"
 )
 
 func runUv(ctx context.Context, pkg string) error {
@@ -42,13 +47,26 @@ func runBrew(ctx context.Context, pkg string) error {
 	return nil
 }
 
+// asRoot runs a system package manager directly when already root, else
+// through sudo or doas (the usual choice on Alpine).
+func asRoot(ctx context.Context, name string, args ...string) *exec.Cmd {
+	if os.Geteuid() == 0 {
+		return exec.CommandContext(ctx, name, args...)
+	}
+	wrapper := "sudo"
+	if !has("sudo") && has("doas") {
+		wrapper = "doas"
+	}
+	return exec.CommandContext(ctx, wrapper, append([]string{name}, args...)...)
+}
+
 func runApt(ctx context.Context, pkg string) error {
 	// TODO: sudo apt-get update && sudo apt-get install -y pkg
-	cmd := exec.CommandContext(ctx, "sudo", "apt-get", "update")
+	cmd := asRoot(ctx, "apt-get", "update")
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("apt-get update failed: %v\n%s", err, out)
 	}
-	cmd = exec.CommandContext(ctx, "sudo", "apt-get", "install", "-y", pkg)
+	cmd = asRoot(ctx, "apt-get", "install", "-y", pkg)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("apt-get install failed: %v\n%s", err, out)
@@ -66,8 +84,17 @@ func runPipx(ctx context.Context, pkg string) error {
 	return nil
 }
 
+func runApk(ctx context.Context, pkg string) error {
+	cmd := asRoot(ctx, "apk", "add", "--no-cache", pkg)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("apk add failed: %v\n%s", err, out)
+	}
+	return nil
+}
+
 func runDnf(ctx context.Context, pkg string) error {
-	cmd := exec.CommandContext(ctx, "sudo", "dnf", "install", "-y", pkg)
+	cmd := asRoot(ctx, "dnf", "install", "-y", pkg)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("dnf install failed: %v\n%s", err, out)
@@ -76,7 +103,7 @@ func runDnf(ctx context.Context, pkg string) error {
 }
 
 func runPacman(ctx context.Context, pkg string) error {
-	cmd := exec.CommandContext(ctx, "sudo", "pacman", "-Sy", pkg)
+	cmd := asRoot(ctx, "pacman", "-Sy", pkg)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("pacman install failed: %v\n%s", err, out)
@@ -85,10 +112,98 @@ func runPacman(ctx context.Context, pkg string) error {
 }
 
 func runZypper(ctx context.Context, pkg string) error {
-	cmd := exec.CommandContext(ctx, "sudo", "zypper", "install", "-y", pkg)
+	cmd := asRoot(ctx, "zypper", "install", "-y", pkg)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("zypper install failed: %v\n%s", err, out)
 	}
 	return nil
 }
+
+func runGo(ctx context.Context, pkg string) error {
+	binDir := getXdgBinDir()
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "go", "install", pkg)
+	cmd.Env = append(os.Environ(), "GOBIN="+binDir)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("go install failed: %v\n%s", err, out)
+	}
+	return nil
+}
+
+// runCargo installs crate (optionally crate@version) with cargo binstall
+// when available, else builds it with cargo install. Both use the parent of
+// the bin dir as root, so binaries land in the bin dir itself.
+func runCargo(ctx context.Context, crate string, locked bool) error {
+	binDir := getXdgBinDir()
+	if filepath.Base(binDir) != "bin" {
+		return fmt.Errorf("cargo install needs a bin dir named bin, got %s", binDir)
+	}
+	args := []string{"install", "--root", filepath.Dir(binDir)}
+	if has("cargo-binstall") {
+		args = []string{"binstall", "--no-confirm", "--root", filepath.Dir(binDir)}
+	}
+	if locked {
+		args = append(args, "--locked")
+	}
+	cmd := exec.CommandContext(ctx, "cargo", append(args, crate)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cargo %s failed: %v\n%s", args[0], err, out)
+	}
+	return nil
+}
+
+// runMise installs plugin at version ("latest" when not pinned, or a fuzzy
+// pin like "20") and makes it the user's global default, with mise or else
+// asdf.
+func runMise(ctx context.Context, plugin, version string) error {
+	if has("mise") {
+		cmd := exec.CommandContext(ctx, "mise", "use", "--global", "--yes", plugin+"@"+version)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("mise use failed: %v\n%s", err, out)
+		}
+		return nil
+	}
+
+	// asdf needs the plugin first; adding an existing one fails harmlessly.
+	_ = exec.CommandContext(ctx, "asdf", "plugin", "add", plugin).Run()
+	// asdf wants an exact version; "asdf latest node 20" resolves a prefix.
+	if _, ok := semver.Exact(version); !ok {
+		args := []string{"latest", plugin}
+		if version != "latest" {
+			args = append(args, version)
+		}
+		out, err := exec.CommandContext(ctx, "asdf", args...).Output()
+		if err != nil {
+			return fmt.Errorf("asdf latest failed: %v", err)
+		}
+		version = strings.TrimSpace(string(out))
+	}
+	cmd := exec.CommandContext(ctx, "asdf", "install", plugin, version)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("asdf install failed: %v\n%s", err, out)
+	}
+	// asdf 0.16 replaced "global" with "set --home".
+	cmd = exec.CommandContext(ctx, "asdf", "set", "--home", plugin, version)
+	if _, err := cmd.CombinedOutput(); err != nil {
+		cmd = exec.CommandContext(ctx, "asdf", "global", plugin, version)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("asdf global failed: %v\n%s", err, out)
+		}
+	}
+	return nil
+}
+
+func runNix(ctx context.Context, ref string) error {
+	// Flakes are still experimental on many installs; enable them per call.
+	cmd := exec.CommandContext(ctx, "nix", "--extra-experimental-features", "nix-command flakes", "profile", "install", ref)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("nix profile install failed: %v\n%s", err, out)
+	}
+	return nil
+}
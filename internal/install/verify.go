@@ -0,0 +1,113 @@
+package install
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/semver"
+)
+
+var defaultVersionRe = regexp.MustCompile(`\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z.]+)?`)
+
+// Installed reports whether the verify command of it succeeds and, when the
+// item has a version, whether the reported version satisfies it.
+func Installed(it catalog.Item) bool {
+	_, ok := verify(it)
+	return ok
+}
+
+// Present reports whether the binary of the verify command of it is on PATH
+// or in the user bin dir, without running it. It is cheap enough for shell
+// startup.
+func Present(it catalog.Item) bool {
+	_, ok := verifyBin(it)
+	return ok
+}
+
+// verifyBin finds the binary the verify command of it runs. Binaries missing
+// from PATH are also looked up in the user bin dir.
+func verifyBin(it catalog.Item) (string, bool) {
+	parts := strings.Fields(it.Verify)
+	if len(parts) == 0 {
+		return "", false
+	}
+	if p, err := exec.LookPath(parts[0]); err == nil {
+		return p, true
+	}
+	p := filepath.Join(BinDir(), parts[0])
+	if fi, err := os.Stat(p); err == nil && !fi.IsDir() && fi.Mode()&0o111 != 0 {
+		return p, true
+	}
+	return parts[0], false
+}
+
+// verify runs the verify command of it and returns the version found in its
+// output.
+func verify(it catalog.Item) (string, bool) {
+	bin, ok := verifyBin(it)
+	if !ok {
+		return "", false
+	}
+	out, err := exec.Command(bin, strings.Fields(it.Verify)[1:]...).CombinedOutput()
+	if err != nil {
+		return "", false
+	}
+
+	found := parseVersion(it, string(out))
+	if it.Version == "" {
+		return found, true
+	}
+	c, err := semver.ParseConstraint(it.Version)
+	if err != nil {
+		// Pins such as "lts" come from mise.toml or .tool-versions; mise
+		// resolves them, so a working binary is all that can be checked.
+		return found, it.Strategy.Mise != ""
+	}
+	v, err := semver.Parse(found)
+	if err != nil {
+		return found, false
+	}
+	return found, c.Check(v)
+}
+
+// parseVersion extracts the version from verify output using the item's
+// version_regex (its first group, if any) or a generic dotted-version pattern.
+func parseVersion(it catalog.Item, out string) string {
+	re := defaultVersionRe
+	if it.VersionRegex != "" {
+		var err error
+		if re, err = regexp.Compile(it.VersionRegex); err != nil {
+			return ""
+		}
+	}
+	m := re.FindStringSubmatch(out)
+	switch {
+	case m == nil:
+		return ""
+	case len(m) > 1:
+		return m[1]
+	}
+	return m[0]
+}
+
+// pin appends the exact version of it to pkg using the package manager's
+// separator ("==" for pipx, "@" for npm, ...). Ranges are not pinned.
+func pin(it catalog.Item, pkg, sep string) string {
+	if v, ok := semver.Exact(it.Version); ok {
+		return pkg + sep + v
+	}
+	return pkg
+}
+
+// goPin is the go install argument of it: module@vX.Y.Z when pinned,
+// module@latest otherwise.
+func goPin(it catalog.Item) string {
+	if v, ok := semver.Exact(it.Version); ok {
+		return it.Strategy.Go + "@v" + v
+	}
+	return it.Strategy.Go + "@latest"
+}
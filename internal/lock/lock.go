@@ -0,0 +1,74 @@
+// Package lock reads and writes dev-gadgets.lock, which records how each
+// item was installed so a team can reproduce the same tool set.
+package lock
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is the lockfile name, kept at the repository root.
+const File = "dev-gadgets.lock"
+
+const header = "# Generated by dev-gadgets install. Do not edit by hand.\n"
+
+// Entry records one installed item.
+type Entry struct {
+	ID string `yaml:"id"`
+	// Strategy that installed the item. For an item that was already
+	// present, the strategy that would install it on the locking machine.
+	Strategy string `yaml:"strategy,omitempty"`
+	Version  string `yaml:"version,omitempty"` // as reported by verify
+	Source   string `yaml:"source,omitempty"`  // package spec or download URL
+	SHA256   string `yaml:"sha256,omitempty"`  // of the downloaded artifact
+}
+
+type Lock struct {
+	Items []Entry `yaml:"items"`
+}
+
+// Read loads the lockfile at path.
+func Read(path string) (*Lock, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l Lock
+	if err := yaml.Unmarshal(b, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Write saves the lockfile to path, entries sorted by ID.
+func (l *Lock) Write(path string) error {
+	slices.SortFunc(l.Items, func(a, b Entry) int { return strings.Compare(a.ID, b.ID) })
+	b, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(header), b...), 0o644)
+}
+
+func (l *Lock) Get(id string) (Entry, bool) {
+	if l == nil {
+		return Entry{}, false
+	}
+	i := slices.IndexFunc(l.Items, func(e Entry) bool { return e.ID == id })
+	if i < 0 {
+		return Entry{}, false
+	}
+	return l.Items[i], true
+}
+
+// Set adds e or replaces the entry with the same ID.
+func (l *Lock) Set(e Entry) {
+	if i := slices.IndexFunc(l.Items, func(x Entry) bool { return x.ID == e.ID }); i >= 0 {
+		l.Items[i] = e
+		return
+	}
+	l.Items = append(l.Items, e)
+}
@@ -0,0 +1,223 @@
+// Package semver parses versions and the version constraints used by the
+// catalog's version field.
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a dotted version; missing components are zero.
+type Version struct {
+	Major, Minor, Patch int
+	Pre                 string
+
+	parts int // number of numeric components written
+}
+
+var versionRe = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Parse parses versions like "1", "v1.2" or "1.2.3-rc.1".
+func Parse(s string) (Version, error) {
+	m := versionRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	v := Version{Pre: m[4], parts: 1}
+	v.Major, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		v.Minor, _ = strconv.Atoi(m[2])
+		v.parts++
+	}
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+		v.parts++
+	}
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1. Pre-releases sort before their release.
+func Compare(a, b Version) int {
+	for _, d := range [...]int{a.Major - b.Major, a.Minor - b.Minor, a.Patch - b.Patch} {
+		if d != 0 {
+			if d < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case a.Pre == b.Pre:
+		return 0
+	case a.Pre == "":
+		return 1
+	case b.Pre == "":
+		return -1
+	}
+	return comparePre(a.Pre, b.Pre)
+}
+
+// comparePre orders pre-release tags the semver way: dot-separated
+// identifiers, numeric ones compared as numbers and before alphanumeric
+// ones, and a shorter tag first when it is a prefix of the other.
+func comparePre(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, errX := strconv.Atoi(as[i])
+		y, errY := strconv.Atoi(bs[i])
+		var d int
+		switch {
+		case errX == nil && errY == nil:
+			d = x - y
+		case errX == nil:
+			d = -1
+		case errY == nil:
+			d = 1
+		default:
+			d = strings.Compare(as[i], bs[i])
+		}
+		if d != 0 {
+			if d < 0 {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// Exact reports the version s pins to, when s is a single full version
+// ("1.2.3", "v1.2.3" or "=1.2.3") rather than a range.
+func Exact(s string) (string, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "=")
+	v, err := Parse(s)
+	if err != nil || v.parts < 3 {
+		return "", false
+	}
+	return strings.TrimPrefix(s, "v"), true
+}
+
+// Constraint is a set of alternatives ("||"), each a set of comparisons that
+// must all hold (separated by commas or spaces).
+type Constraint struct {
+	alts [][]term
+}
+
+type term struct {
+	op string
+	v  Version
+}
+
+// ParseConstraint accepts exact versions, partial versions ("14" is 14.x),
+// comparisons (=, !=, >, >=, <, <=), caret (^1.2) and tilde (~1.2) ranges.
+func ParseConstraint(s string) (Constraint, error) {
+	var c Constraint
+	for _, alt := range strings.Split(s, "||") {
+		var terms []term
+		var pending string // an operator written apart from its version, as in ">= 14"
+		for _, f := range strings.FieldsFunc(alt, func(r rune) bool { return r == ',' || r == ' ' }) {
+			f = pending + f
+			if strings.Trim(f, "=!<>^~") == "" {
+				pending = f
+				continue
+			}
+			pending = ""
+			i := strings.IndexFunc(f, func(r rune) bool { return r == 'v' || (r >= '0' && r <= '9') })
+			if i < 0 {
+				return c, fmt.Errorf("invalid constraint %q", s)
+			}
+			op, rest := f[:i], f[i:]
+			switch op {
+			case "", "=", "==", "!=", ">", ">=", "<", "<=", "^", "~":
+			default:
+				return c, fmt.Errorf("invalid operator %q in %q", op, s)
+			}
+			v, err := Parse(rest)
+			if err != nil {
+				return c, err
+			}
+			terms = append(terms, term{op, v})
+		}
+		if pending != "" {
+			return c, fmt.Errorf("operator %q without a version in %q", pending, s)
+		}
+		if len(terms) == 0 {
+			return c, fmt.Errorf("empty constraint %q", s)
+		}
+		c.alts = append(c.alts, terms)
+	}
+	return c, nil
+}
+
+// Check reports whether v satisfies the constraint.
+func (c Constraint) Check(v Version) bool {
+	for _, terms := range c.alts {
+		ok := true
+		for _, t := range terms {
+			if !t.check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (t term) check(v Version) bool {
+	cmp := Compare(v, t.v)
+	switch t.op {
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "^":
+		// Same left-most non-zero component: ^1.2 is <2, ^0.2 is <0.3 and
+		// ^0.0.3 is 0.0.3 only.
+		if cmp < 0 || v.Major != t.v.Major {
+			return false
+		}
+		if t.v.Major != 0 || t.v.parts < 2 {
+			return true
+		}
+		if v.Minor != t.v.Minor {
+			return false
+		}
+		return t.v.Minor != 0 || t.v.parts < 3 || v.Patch == t.v.Patch
+	case "~":
+		// Same major, and same minor when one was given.
+		if cmp < 0 || v.Major != t.v.Major {
+			return false
+		}
+		return t.v.parts < 2 || v.Minor == t.v.Minor
+	}
+	// Exact, or prefix match for partial versions.
+	if t.v.parts == 3 {
+		return cmp == 0
+	}
+	return v.Major == t.v.Major && (t.v.parts < 2 || v.Minor == t.v.Minor)
+}
@@ -0,0 +1,121 @@
+package semver
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.2", "1.2.0", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+	}
+	for _, tt := range tests {
+		a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+		if got := Compare(a, b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Compare(b, a); got != -tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"14.2.1", "14.2.1", true},
+		{"14.2.1", "14.2.2", false},
+		{"=14.2.1", "14.2.1", true},
+		{"14", "14.9.0", true},
+		{"14", "15.0.0", false},
+		{"14.2", "14.2.7", true},
+		{"14.2", "14.3.0", false},
+		{">=14", "14.0.0", true},
+		{">=14", "13.9.9", false},
+		{">= 14", "14.1.0", true},
+		{">= 14", "13.0.0", false},
+		{">= 1.2, < 2", "1.9.0", true},
+		{">= 1.2, < 2", "2.0.0", false},
+		{">=1.2 <2", "1.1.0", false},
+		{"> 1.0.0", "1.0.1", true},
+		{"!= 1.0.0", "1.0.0", false},
+		{"<1 || >=3", "3.1.0", true},
+		{"<1 || >=3", "2.0.0", false},
+		{"^2.1", "2.9.0", true},
+		{"^2.1", "2.0.9", false},
+		{"^2.1", "3.0.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.0", "0.0.9", true},
+		{"^0.0", "0.1.0", false},
+		{"^0", "0.9.0", true},
+		{"^ 1.2", "1.3.0", true},
+		{"~1.2", "1.2.9", true},
+		{"~1.2", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{">=1.0.0-rc.2", "1.0.0-rc.10", true},
+		{"<1.0.0", "1.0.0-rc.1", true},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q): %v", tt.constraint, err)
+			continue
+		}
+		if got := c.Check(mustParse(t, tt.version)); got != tt.want {
+			t.Errorf("%q.Check(%s) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"", "latest", ">=", ">= 1 <", "=> 1", "1.2.3.4", "1 ||"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q): want an error", s)
+		}
+	}
+}
+
+func TestExact(t *testing.T) {
+	tests := []struct {
+		s, want string
+		ok      bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"v1.2.3", "1.2.3", true},
+		{"=1.2.3-rc.1", "1.2.3-rc.1", true},
+		{"1.2", "", false},
+		{">=1.2.3", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := Exact(tt.s); got != tt.want || ok != tt.ok {
+			t.Errorf("Exact(%q) = %q, %v; want %q, %v", tt.s, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) Version {
+	t.Helper()
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
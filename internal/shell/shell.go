@@ -0,0 +1,160 @@
+// Package shell renders the shell integration of catalog items and keeps it
+// in a managed block of the user's rc file.
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+const (
+	beginMarker = "# >>> dev-gadgets shell-init >>>"
+	endMarker   = "# <<< dev-gadgets shell-init <<<"
+)
+
+// Snippet renders the PATH, env, init and completion lines of items for sh,
+// starting with binDir on PATH.
+func Snippet(sh, binDir string, items []catalog.Item) (string, error) {
+	if !slices.Contains(catalog.Shells, sh) {
+		return "", fmt.Errorf("unsupported shell %q (supported: %s)", sh, strings.Join(catalog.Shells, ", "))
+	}
+
+	var b strings.Builder
+	b.WriteString("# dev-gadgets\n")
+	b.WriteString(pathLine(sh, binDir))
+	if sh == "zsh" {
+		// Completions installed from release archives; compinit reads fpath.
+		fmt.Fprintf(&b, "fpath=(%s $fpath)\n", quote(sh, filepath.Dir(CompletionPath(sh, ""))))
+	}
+	for _, it := range items {
+		s := it.Shell
+		if s == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n# %s\n", it.ID)
+		for _, dir := range s.Path {
+			b.WriteString(pathLine(sh, expand(dir)))
+		}
+		keys := make([]string, 0, len(s.Env))
+		for k := range s.Env {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if sh == "fish" {
+				fmt.Fprintf(&b, "set -gx %s %s\n", k, quote(sh, s.Env[k]))
+			} else {
+				fmt.Fprintf(&b, "export %s=%s\n", k, quote(sh, s.Env[k]))
+			}
+		}
+		for _, line := range []string{s.Init[sh], s.Completions[sh]} {
+			if line = strings.TrimSpace(line); line != "" {
+				b.WriteString(line + "\n")
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+func pathLine(sh, dir string) string {
+	q := quote(sh, dir)
+	if sh == "fish" {
+		return fmt.Sprintf("fish_add_path -g %s\n", q)
+	}
+	return fmt.Sprintf("case \":$PATH:\" in *:%s:*) ;; *) export PATH=%s:\"$PATH\" ;; esac\n", q, q)
+}
+
+// quote single-quotes s for sh, so nothing in it is expanded. Inside fish
+// single quotes only \ and ' need escaping.
+func quote(sh, s string) string {
+	if sh == "fish" {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func expand(dir string) string {
+	if rest, ok := strings.CutPrefix(dir, "~"); ok {
+		dir = xdg.Home + rest
+	}
+	return os.ExpandEnv(dir)
+}
+
+// CompletionPath returns where the completion script of command goes for sh,
+// in the per-user directories bash-completion, zsh (via fpath) and fish load.
+func CompletionPath(sh, command string) string {
+	switch sh {
+	case "zsh":
+		return filepath.Join(xdg.DataHome, "zsh", "site-functions", "_"+command)
+	case "fish":
+		return filepath.Join(xdg.ConfigHome, "fish", "completions", command+".fish")
+	}
+	return filepath.Join(xdg.DataHome, "bash-completion", "completions", command)
+}
+
+// RCFile returns the rc file the managed block is written to for sh.
+func RCFile(sh string) string {
+	switch sh {
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc")
+		}
+		return filepath.Join(xdg.Home, ".zshrc")
+	case "fish":
+		return filepath.Join(xdg.ConfigHome, "fish", "config.fish")
+	}
+	return filepath.Join(xdg.Home, ".bashrc")
+}
+
+// WriteBlock replaces the managed block in path with content, appending the
+// block when the file has none yet.
+func WriteBlock(path, content string) error {
+	// Write through symlinks kept by dotfile managers.
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	block := beginMarker + "\n" + strings.TrimRight(content, "\n") + "\n" + endMarker + "\n"
+
+	text := string(b)
+	start := strings.Index(text, beginMarker)
+	end := strings.Index(text, endMarker)
+	switch {
+	case start >= 0 && end > start:
+		text = text[:start] + block + strings.TrimPrefix(text[end+len(endMarker):], "\n")
+	case start >= 0 || end >= 0:
+		return fmt.Errorf("%s: unbalanced dev-gadgets markers, fix them by hand", path)
+	default:
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if text != "" {
+			text += "\n"
+		}
+		text += block
+	}
+
+	mode := fs.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".dev-gadgets.tmp"
+	if err := os.WriteFile(tmp, []byte(text), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
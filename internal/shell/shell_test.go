@@ -0,0 +1,57 @@
+package shell
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/pirpedro/dev-gadgets/internal/catalog"
+)
+
+func TestSnippetQuoting(t *testing.T) {
+	value := `a\b "c" $HOME 'd' é`
+	dir := `/opt/it's "odd" \ é`
+	items := []catalog.Item{{
+		ID:    "tool",
+		Shell: &catalog.Shell{Path: []string{dir}, Env: map[string]string{"TOOL_VALUE": value}},
+	}}
+	for _, sh := range []string{"sh", "bash", "zsh"} {
+		t.Run(sh, func(t *testing.T) {
+			if _, err := exec.LookPath(sh); err != nil {
+				t.Skip(sh, "not installed")
+			}
+			flavor := sh
+			if sh == "sh" {
+				flavor = "bash"
+			}
+			snippet, err := Snippet(flavor, "/bin dir", items)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Sourcing the snippet twice must not add the dirs twice.
+			script := snippet + snippet + `printf '%s\n' "$TOOL_VALUE" "$PATH"`
+			cmd := exec.Command(sh, "-c", script)
+			cmd.Env = []string{"PATH=/usr/bin:/bin", "HOME=/home/x"}
+			out, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("%v\n%s\nscript:\n%s", err, out, script)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("output = %q", out)
+			}
+			if lines[0] != value {
+				t.Errorf("TOOL_VALUE = %q, want %q", lines[0], value)
+			}
+			if want := dir + ":/bin dir:/usr/bin:/bin"; lines[1] != want {
+				t.Errorf("PATH = %q, want %q", lines[1], want)
+			}
+		})
+	}
+}
+
+func TestQuoteFish(t *testing.T) {
+	if got, want := quote("fish", `a\b 'c' $d`), `'a\\b \'c\' $d'`; got != want {
+		t.Errorf("quote = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+// Package toolversions reads and updates the version pins of asdf's
+// .tool-versions and mise's mise.toml, so dev-gadgets and those managers
+// agree on what a repo uses.
+package toolversions
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	ToolVersions = ".tool-versions"
+	MiseToml     = "mise.toml"
+)
+
+// Find returns the pin file of dir: mise.toml (or .mise.toml) when present,
+// else .tool-versions, which may not exist yet.
+func Find(dir string) string {
+	for _, name := range []string{MiseToml, "." + MiseToml} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return filepath.Join(dir, name)
+		}
+	}
+	return filepath.Join(dir, ToolVersions)
+}
+
+// Read returns the first pinned version of every tool in path. A missing
+// file has no pins.
+func Read(path string) (map[string]string, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	pins := map[string]string{}
+	if !isToml(path) {
+		for _, l := range lines {
+			f := strings.Fields(strings.SplitN(l, "#", 2)[0])
+			if len(f) >= 2 {
+				pins[f[0]] = f[1]
+			}
+		}
+		return pins, nil
+	}
+	start, end := tomlTools(lines)
+	if start < 0 {
+		return pins, nil
+	}
+	for _, l := range lines[start:end] {
+		if key, val, ok := tomlPin(l); ok {
+			pins[key] = val
+		}
+	}
+	return pins, nil
+}
+
+// Write sets the given pins in path, keeping every other line as is.
+func Write(path string, pins map[string]string) error {
+	lines, err := readLines(path)
+	if err != nil {
+		return err
+	}
+	tools := make([]string, 0, len(pins))
+	for t := range pins {
+		tools = append(tools, t)
+	}
+	slices.Sort(tools)
+
+	for _, tool := range tools {
+		if !isToml(path) {
+			line := tool + " " + pins[tool]
+			i := slices.IndexFunc(lines, func(l string) bool {
+				f := strings.Fields(l)
+				return len(f) > 0 && f[0] == tool
+			})
+			if i >= 0 {
+				lines[i] = line
+			} else {
+				lines = append(lines, line)
+			}
+			continue
+		}
+
+		line := fmt.Sprintf("%s = %q", tomlKey(tool), pins[tool])
+		start, end := tomlTools(lines)
+		if start < 0 {
+			if len(lines) > 0 && lines[len(lines)-1] != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, "[tools]", line)
+			continue
+		}
+		i := slices.IndexFunc(lines[start:end], func(l string) bool {
+			key, _, ok := tomlPin(l)
+			return ok && key == tool
+		})
+		if i >= 0 {
+			lines[start+i] = line
+			continue
+		}
+		// Insert after the last non-blank line of the section.
+		at := end
+		for at > start && strings.TrimSpace(lines[at-1]) == "" {
+			at--
+		}
+		lines = slices.Insert(lines, at, line)
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644)
+}
+
+func readLines(path string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(string(b), "\n"), "\n"), nil
+}
+
+func isToml(path string) bool { return strings.HasSuffix(path, ".toml") }
+
+// tomlTools returns the line range of the [tools] table, or -1 when the
+// file has none.
+func tomlTools(lines []string) (start, end int) {
+	start, end = -1, len(lines)
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if !strings.HasPrefix(l, "[") {
+			continue
+		}
+		if start >= 0 {
+			return start, i
+		}
+		if l == "[tools]" {
+			start = i + 1
+		}
+	}
+	if start < 0 {
+		return -1, -1
+	}
+	return start, end
+}
+
+var (
+	tomlPinRe   = regexp.MustCompile(`^\s*("[^"]+"|[A-Za-z0-9_:./@-]+)\s*=\s*(.*)$`)
+	tomlFirstRe = regexp.MustCompile(`"([^"]*)"`)
+	tomlBareRe  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// tomlPin parses `tool = "1.2"`, `tool = ["1.2", "1.1"]` and
+// `tool = { version = "1.2" }` lines.
+func tomlPin(line string) (key, val string, ok bool) {
+	m := tomlPinRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	v := tomlFirstRe.FindStringSubmatch(m[2])
+	if v == nil {
+		return "", "", false
+	}
+	return strings.Trim(m[1], `"`), v[1], true
+}
+
+func tomlKey(tool string) string {
+	if tomlBareRe.MatchString(tool) {
+		return tool
+	}
+	return fmt.Sprintf("%q", tool)
+}
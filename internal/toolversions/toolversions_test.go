@@ -0,0 +1,53 @@
+package toolversions
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+		want                map[string]string
+	}{
+		{"tool-versions", ToolVersions, "node 20.1.0 18.0.0\n# comment\npython 3.12 # trailing\n", map[string]string{"node": "20.1.0", "python": "3.12"}},
+		{"toml tools", MiseToml, "[env]\nFOO = \"1\"\n\n[tools]\nnode = \"20\"\n\"npm:prettier\" = [\"3.0\", \"2.0\"]\ngo = { version = \"1.24\" }\n\n[settings]\nx = \"y\"\n", map[string]string{"node": "20", "npm:prettier": "3.0", "go": "1.24"}},
+		{"toml without tools", MiseToml, "[env]\nFOO = \"1\"\n", map[string]string{}},
+		{"empty toml", MiseToml, "", map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("Read = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteAddsToolsTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), MiseToml)
+	if err := os.WriteFile(path, []byte("[env]\nFOO = \"1\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(path, map[string]string{"node": "20"}); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	if want := "[env]\nFOO = \"1\"\n\n[tools]\nnode = \"20\"\n"; string(b) != want {
+		t.Errorf("file = %q, want %q", b, want)
+	}
+	got, err := Read(path)
+	if err != nil || got["node"] != "20" {
+		t.Errorf("Read = %v, %v", got, err)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
-	"strings"
+	"slices"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -13,6 +13,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pirpedro/dev-gadgets/internal/catalog"
+	"github.com/pirpedro/dev-gadgets/internal/install"
 )
 
 var draculaBg = lipgloss.Color("#282a36")
@@ -37,6 +38,7 @@ type SelectItem struct {
 	ID        string
 	Name      string
 	Desc      string
+	Group     string // section header the item is listed under
 	Installed bool
 }
 
@@ -44,6 +46,11 @@ func (i SelectItem) Title() string       { return i.Name }
 func (i SelectItem) Description() string { return i.Desc }
 func (i SelectItem) FilterValue() string { return i.Name }
 
+// Cabeçalho de seção, não selecionável
+type sectionHeader string
+
+func (h sectionHeader) FilterValue() string { return "" }
+
 type extraKeys struct {
 	Select      key.Binding
 	SelectAll   key.Binding
@@ -89,6 +96,10 @@ func (d selectDelegate) Render(w io.Writer, m list.Model, index int, item list.I
 	if item == nil {
 		return
 	}
+	if h, ok := item.(sectionHeader); ok {
+		fmt.Fprintln(w, lipgloss.NewStyle().Foreground(draculaPurple).Bold(true).Render("── "+string(h)))
+		return
+	}
 	it, ok := item.(SelectItem)
 	if !ok {
 		return
@@ -116,15 +127,30 @@ func (d selectDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil
 func NewSelectItemsModel(items []SelectItem) SelectItemsModel {
 	selected := map[string]bool{}
 	delegate := selectDelegate{selected: selected}
-	height := len(items) + 5
+
+	// Agrupa por seção mantendo a ordem de chegada
+	items = slices.Clone(items)
+	var groups []string
+	for _, it := range items {
+		if !slices.Contains(groups, it.Group) {
+			groups = append(groups, it.Group)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b SelectItem) int {
+		return slices.Index(groups, a.Group) - slices.Index(groups, b.Group)
+	})
+	var listItems []list.Item
+	for i, it := range items {
+		if len(groups) > 1 && (i == 0 || items[i-1].Group != it.Group) {
+			listItems = append(listItems, sectionHeader(it.Group))
+		}
+		listItems = append(listItems, it)
+	}
+
+	height := len(listItems) + 5
 	if height > 20 {
 		height = 20
 	}
-	// populate list with items to avoid nil entries
-	listItems := make([]list.Item, len(items))
-	for i := range items {
-		listItems[i] = items[i]
-	}
 	l := list.New(listItems, delegate, 0, height)
 	l.Title = "Select tools to install"
 	l.SetShowStatusBar(false)
@@ -138,6 +164,9 @@ func NewSelectItemsModel(items []SelectItem) SelectItemsModel {
 			ek.SelectAll,
 			ek.DeselectAll}
 	}
+	if _, ok := l.SelectedItem().(sectionHeader); ok {
+		l.CursorDown()
+	}
 
 	return SelectItemsModel{
 		list:     l,
@@ -161,8 +190,20 @@ func (m SelectItemsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "up", "k":
 			m.list.CursorUp()
+			if _, ok := m.list.SelectedItem().(sectionHeader); ok {
+				if m.list.Index() == 0 {
+					m.list.CursorDown()
+				} else {
+					m.list.CursorUp()
+				}
+			}
+			return m, nil
 		case "down", "j":
 			m.list.CursorDown()
+			if _, ok := m.list.SelectedItem().(sectionHeader); ok {
+				m.list.CursorDown()
+			}
+			return m, nil
 		case " ":
 			item, ok := m.list.SelectedItem().(SelectItem)
 			if ok && !item.Installed {
@@ -282,10 +323,5 @@ func IsInstalled(it catalog.Item) bool {
 	if it.Verify == "" {
 		return false
 	}
-	parts := strings.Fields(it.Verify)
-	if len(parts) == 0 {
-		return false
-	}
-	cmd := exec.Command(parts[0], parts[1:]...)
-	return cmd.Run() == nil
+	return install.Installed(it)
 }